@@ -8,6 +8,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
 	"github.com/polarysfoundation/kilocompbot/bot/notificator"
 	"github.com/polarysfoundation/kilocompbot/bot/promotions"
 	"github.com/polarysfoundation/kilocompbot/core"
@@ -18,20 +19,31 @@ import (
 type Backup struct {
 	Group      *groups.Groups
 	Comps      *core.Competition
+	Exclusions *core.Exclusions
+	Roles      *core.Roles
 	Temps      *groups.ActiveTemps
 	Promo      *promotions.Params
+	Bookings   *bookings.Bookings
+	Tracker    *promotions.Tracker
 	DB         *sql.DB
 	Lastupdate int64
+	// campaignsLoaded evita que un error al cargar las campañas las borre en el siguiente backup
+	campaignsLoaded bool
+	statsLoaded     bool
 }
 
-func InitBackup(db *sql.DB, groups *groups.Groups, comps *core.Competition, promo *promotions.Params, temps *groups.ActiveTemps) *Backup {
+func InitBackup(db *sql.DB, groups *groups.Groups, comps *core.Competition, promo *promotions.Params, temps *groups.ActiveTemps, exclusions *core.Exclusions, roles *core.Roles, ads *bookings.Bookings, tracker *promotions.Tracker) *Backup {
 	lastUpdate := time.Now().Unix()
 
 	return &Backup{
 		Group:      groups,
 		Comps:      comps,
+		Exclusions: exclusions,
+		Roles:      roles,
 		Temps:      temps,
 		Promo:      promo,
+		Bookings:   ads,
+		Tracker:    tracker,
 		DB:         db,
 		Lastupdate: lastUpdate,
 	}
@@ -58,8 +70,13 @@ func (b *Backup) HandleSync() {
 	log.Print("creating backup...")
 	b.storeGroups()
 	b.storePromo()
+	b.StoreCampaigns()
+	b.storeAdStats()
 	b.storePurchase()
 	b.storeSale()
+	b.storeDisqualified()
+	b.storeExcluded()
+	b.storeRoles()
 	b.storeEndTime()
 }
 
@@ -68,7 +85,13 @@ func (b *Backup) LoadData(tickers *notificator.Groups) {
 	b.loadGroups(tickers)
 	b.loadPurchase()
 	b.loadSales()
+	b.loadDisqualified()
+	b.loadExcluded()
+	b.loadRoles()
 	b.loadPromo()
+	b.loadCampaigns()
+	b.loadBookings()
+	b.loadAdStats()
 	b.loadTimestamp()
 }
 
@@ -83,12 +106,19 @@ func (b *Backup) loadPromo() {
 	b.Promo.UpdateButtonLink(promo.ButtonLink)
 	b.Promo.UpdateButtonName(promo.ButtonName)
 	b.Promo.Media = promo.Media
+	b.Promo.MediaFileID = promo.MediaFileID
+	b.Promo.MediaType = promo.MediaType
 }
 
 func (b *Backup) loadTimestamp() {
-	for _, group := range b.Group.ActiveGroups {
+	for _, id := range b.Group.IDs() {
+		group, err := b.Group.GetDataGroup(id)
+		if err != nil {
+			continue
+		}
+
 		if group.CompActive {
-			timestamp, err := database.GetEndTime(b.DB, group.ID)
+			timestamp, pausedAt, err := database.GetEndTime(b.DB, group.ID)
 			if err != nil {
 				log.Printf("no se pudo obtener la fecha de culminacion para el grupo %s, por el siguiente error, %v", group.ID, err)
 				continue
@@ -98,6 +128,12 @@ func (b *Backup) loadTimestamp() {
 				log.Printf("hubo error mientras se añadia la fecha de culminacion para el grupo %s", group.ID)
 				continue
 			}
+			if pausedAt > 0 {
+				err = b.Comps.Pause(group.ID, pausedAt)
+				if err != nil {
+					log.Printf("no se pudo restaurar la pausa para el grupo %s: %v", group.ID, err)
+				}
+			}
 		}
 	}
 }
@@ -129,7 +165,7 @@ func (b *Backup) loadGroups(tickers *notificator.Groups) {
 }
 
 func (b *Backup) loadPurchase() {
-	for id := range b.Group.ActiveGroups {
+	for _, id := range b.Group.IDs() {
 		purchases, err := database.GetPurchase(b.DB, id)
 		if err != nil {
 			log.Printf("error obteniendo las compras para el grupo %s: %v", id, err)
@@ -159,7 +195,7 @@ func (b *Backup) loadPurchase() {
 }
 
 func (b *Backup) loadSales() {
-	for id := range b.Group.ActiveGroups {
+	for _, id := range b.Group.IDs() {
 		sales, err := database.GetSale(b.DB, id)
 		if err != nil {
 			log.Printf("error obteniendo las compras para el grupo %s: %v", id, err)
@@ -167,6 +203,14 @@ func (b *Backup) loadSales() {
 		}
 
 		if len(sales) > 0 {
+			if !b.Comps.BlackListExist(id) {
+				err := b.Comps.NewBlacklist(id)
+				if err != nil {
+					log.Printf("no se pudo crear la blacklist, error: %v", err)
+					continue
+				}
+			}
+
 			for _, sale := range sales {
 				err := b.Comps.BlackList[id].StoreSale(sale)
 				if err != nil {
@@ -181,61 +225,250 @@ func (b *Backup) loadSales() {
 	}
 }
 
+func (b *Backup) loadDisqualified() {
+	for _, id := range b.Group.IDs() {
+		disqualified, err := database.GetDisqualified(b.DB, id)
+		if err != nil {
+			log.Printf("error obteniendo las wallets descalificadas para el grupo %s: %v", id, err)
+			continue
+		}
+
+		for _, disqualification := range disqualified {
+			// Las compras de la wallet vuelven a la descalificacion para poder restaurarlas con /unban
+			banned, err := b.Comps.Ban(id, disqualification.Wallet, disqualification.Reason, disqualification.By)
+			if err != nil {
+				log.Printf("error cargando la wallet descalificada %s para el grupo %s: %v", disqualification.Wallet, id, err)
+				continue
+			}
+
+			banned.Timestamp = disqualification.Timestamp
+		}
+	}
+}
+
+func (b *Backup) loadExcluded() {
+	excluded, err := database.GetExcluded(b.DB)
+	if err != nil {
+		log.Printf("error obteniendo las wallets excluidas: %v", err)
+		return
+	}
+
+	for scope, wallets := range excluded {
+		for _, wallet := range wallets {
+			err := b.Exclusions.Add(scope, wallet)
+			if err != nil {
+				log.Printf("error cargando la wallet excluida %s: %v", wallet, err)
+			}
+		}
+	}
+}
+
+func (b *Backup) loadRoles() {
+	roles, err := database.GetRoles(b.DB)
+	if err != nil {
+		log.Printf("error obteniendo los roles de los grupos: %v", err)
+		return
+	}
+
+	for id, assignments := range roles {
+		for _, assignment := range assignments {
+			err := b.Roles.Set(id, assignment.UserID, assignment.Username, assignment.Role)
+			if err != nil {
+				log.Printf("error cargando el rol del usuario %d en el grupo %s: %v", assignment.UserID, id, err)
+			}
+		}
+	}
+}
+
 func (b *Backup) storeGroups() {
-	for _, group := range b.Group.ActiveGroups {
-		err := database.WriteGroups(b.DB, group.ID, group.CompActive, group.JettonAddress, group.Dedust, group.StonFi, group.Emoji)
+	for _, id := range b.Group.IDs() {
+		group, err := b.Group.GetDataGroup(id)
+		if err != nil {
+			continue
+		}
+
+		err = database.WriteGroups(b.DB, group)
 		if err != nil {
 			log.Printf("error guardando grupo %s", group.ID)
 			log.Println("error:", err)
-			return
+			continue
 		}
-		break
 	}
 }
 
 func (b *Backup) storePurchase() {
-	for id, comp := range b.Comps.Comps {
-		for _, purchase := range comp.Purchase {
-			err := database.WritePurchases(b.DB, id, purchase.JettonAddress, purchase.JettonName, purchase.JettonSymbol, purchase.JettonDecimals, purchase.Buyer, purchase.Ton, purchase.Token)
-			if err != nil {
-				log.Printf("error guardando compra para el grupo %s", id)
-				log.Println("error:", err)
-				return
-			}
-			break
+	for _, id := range b.Group.IDs() {
+		comp, err := b.Comps.GetComp(id)
+		if err != nil {
+			continue
+		}
+
+		// Las compras retiradas con /ban se guardan con las demas para que /unban las pueda devolver despues de reiniciar,
+		// loadDisqualified las vuelve a retirar del ranking al cargarlas
+		purchases := comp.List()
+		for _, disqualification := range b.Comps.Banned(id) {
+			purchases = append(purchases, disqualification.Purchases...)
+		}
+
+		err = database.WritePurchases(b.DB, id, purchases)
+		if err != nil {
+			log.Printf("error guardando las compras para el grupo %s", id)
+			log.Println("error:", err)
+			continue
 		}
 	}
 }
 
 func (b *Backup) storeEndTime() {
-	for id, timestamp := range b.Comps.Timestamp {
-		err := database.WriteEndTime(b.DB, id, timestamp)
+	for _, id := range b.Group.IDs() {
+		timestamp, err := b.Comps.GetTimestamp(id)
+		if err != nil {
+			continue
+		}
+
+		err = database.WriteEndTime(b.DB, id, timestamp, b.Comps.PausedAt(id))
 		if err != nil {
 			log.Printf("error guardando el endtime para el grupo %s", id)
 			log.Println("error:", err)
-			return
+			continue
 		}
-		break
-
 	}
 }
 
 func (b *Backup) storeSale() {
-	for id, comp := range b.Comps.BlackList {
-		for _, sale := range comp.Sale {
-			err := database.WritePurchases(b.DB, id, sale.JettonAddress, sale.JettonName, sale.JettonSymbol, sale.JettonDecimals, sale.Seller, sale.Ton, sale.Token)
-			if err != nil {
-				log.Printf("error guardando venta para el grupo %s", id)
-				log.Println("error:", err)
-				return
-			}
-			break
+	for _, id := range b.Group.IDs() {
+		comp, err := b.Comps.GetBlacklist(id)
+		if err != nil {
+			continue
+		}
+
+		err = database.WriteSales(b.DB, id, comp.List())
+		if err != nil {
+			log.Printf("error guardando las ventas para el grupo %s", id)
+			log.Println("error:", err)
+			continue
+		}
+	}
+}
+
+func (b *Backup) storeDisqualified() {
+	for _, id := range b.Group.IDs() {
+		comp, err := b.Comps.GetBlacklist(id)
+		if err != nil {
+			continue
+		}
+
+		err = database.WriteDisqualified(b.DB, id, comp.DisqualifiedList())
+		if err != nil {
+			log.Printf("error guardando las wallets descalificadas para el grupo %s", id)
+			log.Println("error:", err)
+			continue
+		}
+	}
+}
+
+func (b *Backup) storeExcluded() {
+	for _, scope := range b.Exclusions.Scopes() {
+		err := database.WriteExcluded(b.DB, scope, b.Exclusions.List(scope))
+		if err != nil {
+			log.Printf("error guardando las wallets excluidas para %s", scope)
+			log.Println("error:", err)
+			continue
+		}
+	}
+}
+
+func (b *Backup) storeRoles() {
+	for _, id := range b.Roles.Groups() {
+		err := database.WriteRoles(b.DB, id, b.Roles.List(id))
+		if err != nil {
+			log.Printf("error guardando los roles del grupo %s", id)
+			log.Println("error:", err)
+			continue
+		}
+	}
+}
+
+func (b *Backup) loadCampaigns() {
+	campaigns, err := database.GetCampaigns(b.DB)
+	if err != nil {
+		log.Printf("error obteniendo las campañas: %v", err)
+		return
+	}
+
+	lastID, err := database.GetLastCampaignID(b.DB)
+	if err != nil {
+		log.Printf("error obteniendo el ultimo id de campaña: %v", err)
+		return
+	}
+
+	b.Promo.SeedCampaignID(lastID)
+	b.campaignsLoaded = true
+
+	for _, campaign := range campaigns {
+		_, err := b.Promo.AddCampaign(*campaign)
+		if err != nil {
+			log.Printf("error cargando la campaña %d: %v", campaign.ID, err)
 		}
 	}
 }
 
+// StoreCampaigns guarda las campañas sin esperar al siguiente backup, se usa cuando se activa un anuncio pagado
+func (b *Backup) StoreCampaigns() {
+	if !b.campaignsLoaded {
+		return
+	}
+
+	err := database.WriteCampaigns(b.DB, b.Promo.Campaigns(), b.Promo.LastCampaignID())
+	if err != nil {
+		log.Print("error guardando las campañas")
+		log.Println("error:", err)
+	}
+}
+
+// loadBookings restaura las reservas recientes, las pendientes se siguen esperando y las vencidas se descartan con el tiempo
+func (b *Backup) loadBookings() {
+	since := time.Now().Add(-bookings.Retention).Unix()
+
+	list, err := database.GetBookings(b.DB, since)
+	if err != nil {
+		log.Printf("error obteniendo las reservas de anuncios: %v", err)
+		return
+	}
+
+	for _, booking := range list {
+		b.Bookings.Restore(booking)
+	}
+}
+
+func (b *Backup) loadAdStats() {
+	stats, err := database.GetAdStats(b.DB)
+	if err != nil {
+		log.Printf("error obteniendo las estadisticas de los anuncios: %v", err)
+		return
+	}
+
+	b.statsLoaded = true
+
+	for _, stat := range stats {
+		b.Tracker.Restore(*stat)
+	}
+}
+
+func (b *Backup) storeAdStats() {
+	if !b.statsLoaded {
+		return
+	}
+
+	err := database.WriteAdStats(b.DB, b.Tracker.Stats())
+	if err != nil {
+		log.Print("error guardando las estadisticas de los anuncios")
+		log.Println("error:", err)
+	}
+}
+
 func (b *Backup) storePromo() {
-	err := database.WritePromo(b.DB, "promo", b.Promo.AdName, b.Promo.ButtonName, b.Promo.ButtonLink, b.Promo.Media)
+	err := database.WritePromo(b.DB, "promo", b.Promo.AdName, b.Promo.ButtonName, b.Promo.ButtonLink, b.Promo.Media, b.Promo.MediaFileID, b.Promo.MediaType)
 	if err != nil {
 		log.Print("error guardando la promo")
 		log.Println("error:", err)
@@ -0,0 +1,243 @@
+package bookings
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+const (
+	// DefaultSlots son las duraciones y precios en TON que se ofrecen si no se configura AD_SLOTS
+	DefaultSlots = "1d=10,3d=25,7d=50"
+
+	// PaymentWindow es el tiempo que tiene el anunciante para pagar una reserva
+	PaymentWindow = time.Hour
+
+	StatusPending = "pending"
+	StatusPaid    = "paid"
+	StatusExpired = "expired"
+
+	commentPrefix = "AD-"
+	nanoPerTON    = 1_000_000_000
+)
+
+var (
+	errInvalidSlots   = errors.New("error: AD_SLOTS debe tener el formato 1d=10,3d=25")
+	errSlotNotExist   = errors.New("error: la duracion elegida no existe")
+	errDraftNotExist  = errors.New("error: no hay un anuncio pendiente de reservar")
+	errBookingsClosed = errors.New("error: las reservas de anuncios no estan configuradas")
+)
+
+// Slot es una duracion de anuncio que se puede reservar y su precio en nanotons
+type Slot struct {
+	Duration time.Duration
+	Price    *big.Int
+}
+
+// Booking es una reserva de anuncio. Se identifica por Comment, el texto que el anunciante debe adjuntar al pago.
+type Booking struct {
+	Comment  string
+	UserID   int64
+	ChatID   int64
+	Ad       promotions.Campaign
+	Duration time.Duration
+	Price    *big.Int
+	Created  int64
+	Expires  int64
+	Status   string
+	// CampaignID, CampaignEnd y EventID se completan cuando se recibe el pago
+	CampaignID  int64
+	CampaignEnd int64
+	EventID     string
+}
+
+// Bookings guarda los anuncios que esperan que el anunciante elija una duracion y las reservas que esperan su pago
+type Bookings struct {
+	Address  string
+	Slots    []Slot
+	drafts   map[int64]promotions.Campaign
+	bookings map[string]*Booking
+	mutex    sync.RWMutex
+}
+
+func InitBookings(address string, slots []Slot) *Bookings {
+	return &Bookings{
+		Address:  address,
+		Slots:    slots,
+		drafts:   make(map[int64]promotions.Campaign),
+		bookings: make(map[string]*Booking),
+	}
+}
+
+// ParseSlots interpreta una lista como "1d=10,3d=25.5" con la duracion y el precio en TON de cada opcion
+func ParseSlots(value string) ([]Slot, error) {
+	slots := make([]Slot, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		durationValue, priceValue, found := strings.Cut(item, "=")
+		if !found {
+			return nil, errInvalidSlots
+		}
+
+		duration, err := core.ParseDuration(durationValue)
+		if err != nil {
+			return nil, errInvalidSlots
+		}
+
+		price, err := ParseTON(priceValue)
+		if err != nil || price.Sign() <= 0 {
+			return nil, errInvalidSlots
+		}
+
+		slots = append(slots, Slot{Duration: duration, Price: price})
+	}
+
+	if len(slots) == 0 {
+		return nil, errInvalidSlots
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Duration < slots[j].Duration
+	})
+
+	return slots, nil
+}
+
+// ParseTON convierte un monto en TON con hasta 9 decimales, como "12.5", a nanotons
+func ParseTON(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	if whole == "" || len(fraction) > 9 {
+		return nil, errInvalidSlots
+	}
+
+	fraction += strings.Repeat("0", 9-len(fraction))
+
+	nano, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok || nano.Sign() < 0 {
+		return nil, errInvalidSlots
+	}
+
+	return nano, nil
+}
+
+// FormatTON muestra un monto en nanotons como TON sin ceros de mas, por ejemplo 12.5
+func FormatTON(nano *big.Int) string {
+	whole, fraction := new(big.Int).QuoRem(nano, big.NewInt(nanoPerTON), new(big.Int))
+
+	if fraction.Sign() == 0 {
+		return whole.String()
+	}
+
+	decimals := strings.TrimRight(strings.Repeat("0", 9-len(fraction.String()))+fraction.String(), "0")
+
+	return whole.String() + "." + decimals
+}
+
+// Enabled indica si hay una direccion de pagos configurada
+func (b *Bookings) Enabled() bool {
+	return b.Address != "" && len(b.Slots) > 0
+}
+
+// SetDraft guarda el anuncio que el usuario quiere reservar hasta que elija una duracion
+func (b *Bookings) SetDraft(userID int64, ad promotions.Campaign) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.drafts[userID] = ad
+}
+
+// Book crea la reserva del anuncio pendiente del usuario con la duracion elegida
+func (b *Bookings) Book(userID int64, chatID int64, slot int, now time.Time) (*Booking, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.Enabled() {
+		return nil, errBookingsClosed
+	}
+
+	if slot < 0 || slot >= len(b.Slots) {
+		return nil, errSlotNotExist
+	}
+
+	ad, exist := b.drafts[userID]
+	if !exist {
+		return nil, errDraftNotExist
+	}
+
+	comment, err := b.newComment()
+	if err != nil {
+		return nil, err
+	}
+
+	booking := &Booking{
+		Comment:  comment,
+		UserID:   userID,
+		ChatID:   chatID,
+		Ad:       ad,
+		Duration: b.Slots[slot].Duration,
+		Price:    new(big.Int).Set(b.Slots[slot].Price),
+		Created:  now.Unix(),
+		Expires:  now.Add(PaymentWindow).Unix(),
+		Status:   StatusPending,
+	}
+
+	b.bookings[comment] = booking
+	delete(b.drafts, userID)
+
+	return booking, nil
+}
+
+// newComment genera un comentario de pago que no usa ninguna otra reserva. Se debe llamar con el mutex tomado.
+func (b *Bookings) newComment() (string, error) {
+	for {
+		random := make([]byte, 4)
+		if _, err := rand.Read(random); err != nil {
+			return "", err
+		}
+
+		comment := commentPrefix + strings.ToUpper(hex.EncodeToString(random))
+		if _, exist := b.bookings[comment]; !exist {
+			return comment, nil
+		}
+	}
+}
+
+// Restore agrega una reserva guardada en la base de datos
+func (b *Bookings) Restore(booking *Booking) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.bookings[booking.Comment] = booking
+}
+
+// List devuelve una copia de las reservas, las mas recientes primero
+func (b *Bookings) List() []Booking {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	bookings := make([]Booking, 0, len(b.bookings))
+	for _, booking := range b.bookings {
+		bookings = append(bookings, *booking)
+	}
+
+	sort.Slice(bookings, func(i, j int) bool {
+		return bookings[i].Created > bookings[j].Created
+	})
+
+	return bookings
+}
@@ -0,0 +1,179 @@
+package bookings
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/indexer"
+)
+
+const (
+	// checkInterval es cada cuanto se consultan las transferencias recibidas
+	checkInterval = 30 * time.Second
+
+	// paymentGrace es el margen que se espera al indexer antes de dar por vencida una reserva
+	paymentGrace = 10 * time.Minute
+
+	// Retention es el tiempo que se conservan las reservas pagadas o vencidas
+	Retention = 30 * 24 * time.Hour
+)
+
+// TransferSource devuelve las transferencias recientes recibidas por una direccion, indexer.TonAPI en produccion
+type TransferSource interface {
+	IncomingTransfers(address string) ([]*indexer.Transfer, error)
+}
+
+// Watcher activa las reservas cuando llega un pago con su comentario y el monto suficiente
+type Watcher struct {
+	Bookings *Bookings
+	Promo    *promotions.Params
+	Source   TransferSource
+	// OnPaid se llama fuera del mutex con la reserva ya activada
+	OnPaid func(*Booking)
+
+	// seen son las transferencias de la consulta anterior, para no registrar dos veces un pago sin reserva
+	seen map[string]struct{}
+}
+
+func InitWatcher(bookings *Bookings, promo *promotions.Params, source TransferSource, onPaid func(*Booking)) *Watcher {
+	return &Watcher{
+		Bookings: bookings,
+		Promo:    promo,
+		Source:   source,
+		OnPaid:   onPaid,
+		seen:     make(map[string]struct{}),
+	}
+}
+
+func (w *Watcher) HandlePayments() {
+	if !w.Bookings.Enabled() {
+		log.Print("no hay una direccion de pagos configurada, las reservas de anuncios estan desactivadas")
+		return
+	}
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		w.Check(time.Now())
+	}
+}
+
+// Check revisa las transferencias recibidas, activa las reservas pagadas y vence las que no se pagaron a tiempo
+func (w *Watcher) Check(now time.Time) {
+	// Sin reservas pendientes no hace falta consultar la API
+	if !w.Bookings.hasPending() {
+		w.Bookings.expire(now)
+		return
+	}
+
+	transfers, err := w.Source.IncomingTransfers(w.Bookings.Address)
+	if err != nil {
+		log.Printf("no se pudieron leer los pagos de anuncios: %v", err)
+		return
+	}
+
+	seen := make(map[string]struct{}, len(transfers))
+	paid := make([]*Booking, 0)
+
+	for _, transfer := range transfers {
+		seen[transfer.EventID] = struct{}{}
+
+		booking := w.match(transfer, now)
+		if booking != nil {
+			paid = append(paid, booking)
+			continue
+		}
+
+		if _, exist := w.seen[transfer.EventID]; !exist && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(transfer.Comment)), commentPrefix) {
+			log.Printf("pago %s de %s con comentario %q sin una reserva pendiente que coincida", transfer.EventID, transfer.Sender, transfer.Comment)
+		}
+	}
+
+	w.seen = seen
+	w.Bookings.expire(now)
+
+	for _, booking := range paid {
+		if w.OnPaid != nil {
+			w.OnPaid(booking)
+		}
+	}
+}
+
+// match activa la reserva pendiente que corresponde a la transferencia y devuelve una copia, nil si no hay ninguna
+func (w *Watcher) match(transfer *indexer.Transfer, now time.Time) *Booking {
+	b := w.Bookings
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	comment := strings.ToUpper(strings.TrimSpace(transfer.Comment))
+
+	booking, exist := b.bookings[comment]
+	if !exist || booking.Status != StatusPending {
+		return nil
+	}
+
+	if transfer.Timestamp < booking.Created || transfer.Timestamp > booking.Expires {
+		log.Printf("el pago %s de la reserva %s llego fuera de plazo", transfer.EventID, booking.Comment)
+		return nil
+	}
+
+	if transfer.Amount.Cmp(booking.Price) < 0 {
+		log.Printf("el pago %s de la reserva %s es de %s TON y el precio es %s TON", transfer.EventID, booking.Comment, FormatTON(transfer.Amount), FormatTON(booking.Price))
+		return nil
+	}
+
+	// El anuncio empieza cuando se confirma el pago y no cuando se reservo
+	campaign := booking.Ad
+	campaign.ID = 0
+	campaign.Start = now.Unix()
+	campaign.End = now.Add(booking.Duration).Unix()
+	campaign.Weight = 1
+	campaign.Groups = nil
+
+	id, err := w.Promo.AddCampaign(campaign)
+	if err != nil {
+		log.Printf("no se pudo activar el anuncio de la reserva %s: %v", booking.Comment, err)
+		return nil
+	}
+
+	booking.Status = StatusPaid
+	booking.CampaignID = id
+	booking.CampaignEnd = campaign.End
+	booking.EventID = transfer.EventID
+
+	log.Printf("reserva %s pagada con %s, campaña %d activa hasta %s", booking.Comment, transfer.EventID, id, time.Unix(campaign.End, 0).UTC().Format(time.RFC3339))
+
+	paid := *booking
+	return &paid
+}
+
+func (b *Bookings) hasPending() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, booking := range b.bookings {
+		if booking.Status == StatusPending {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expire vence las reservas pendientes fuera de plazo y descarta las que pasaron el tiempo de conservacion
+func (b *Bookings) expire(now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for comment, booking := range b.bookings {
+		switch {
+		case booking.Status == StatusPending && now.Unix() > booking.Expires+int64(paymentGrace.Seconds()):
+			booking.Status = StatusExpired
+		case booking.Status != StatusPending && now.Unix() > booking.Expires+int64(Retention.Seconds()):
+			delete(b.bookings, comment)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package bookings
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/indexer"
+)
+
+const testAddress = "EQtest"
+
+type fakeSource struct {
+	transfers []*indexer.Transfer
+}
+
+func (f *fakeSource) IncomingTransfers(address string) ([]*indexer.Transfer, error) {
+	return f.transfers, nil
+}
+
+func ton(value string) *big.Int {
+	nano, err := ParseTON(value)
+	if err != nil {
+		panic(err)
+	}
+	return nano
+}
+
+func newTestWatcher(t *testing.T, now time.Time) (*Watcher, *fakeSource, *Booking, *[]*Booking) {
+	t.Helper()
+
+	slots, err := ParseSlots("1d=10,3d=25")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bookings := InitBookings(testAddress, slots)
+	bookings.SetDraft(1, promotions.Campaign{AdName: "Buy KILO", ButtonName: "Open", ButtonLink: "https://example.com"})
+
+	booking, err := bookings.Book(1, 1, 0, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := &fakeSource{}
+	paid := make([]*Booking, 0)
+
+	watcher := InitWatcher(bookings, promotions.InitParams(), source, func(b *Booking) {
+		paid = append(paid, b)
+	})
+
+	return watcher, source, booking, &paid
+}
+
+func TestWatcherActivatesPaidBooking(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	watcher, source, booking, paid := newTestWatcher(t, now)
+
+	source.transfers = []*indexer.Transfer{{
+		EventID:   "tx1",
+		Sender:    "EQsender",
+		Amount:    ton("10"),
+		Comment:   " " + booking.Comment + " ",
+		Timestamp: now.Add(time.Minute).Unix(),
+	}}
+
+	watcher.Check(now.Add(2 * time.Minute))
+
+	if len(*paid) != 1 {
+		t.Fatalf("expected one paid booking, got %d", len(*paid))
+	}
+
+	campaigns := watcher.Promo.Campaigns()
+	if len(campaigns) != 1 {
+		t.Fatalf("expected one campaign, got %d", len(campaigns))
+	}
+
+	if got := campaigns[0].End - campaigns[0].Start; got != int64((24 * time.Hour).Seconds()) {
+		t.Errorf("campaign lasts %ds, expected one day", got)
+	}
+
+	if (*paid)[0].Status != StatusPaid || (*paid)[0].CampaignID != campaigns[0].ID || (*paid)[0].CampaignEnd != campaigns[0].End || (*paid)[0].EventID != "tx1" {
+		t.Errorf("unexpected paid booking %+v", (*paid)[0])
+	}
+
+	// La misma transferencia en la siguiente consulta no vuelve a activar la reserva
+	watcher.Check(now.Add(3 * time.Minute))
+
+	if len(*paid) != 1 || len(watcher.Promo.Campaigns()) != 1 {
+		t.Errorf("booking activated twice")
+	}
+}
+
+func TestWatcherIgnoresInvalidPayments(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+
+	tests := []struct {
+		name     string
+		transfer func(b *Booking) *indexer.Transfer
+	}{
+		{"underpaid", func(b *Booking) *indexer.Transfer {
+			return &indexer.Transfer{EventID: "tx", Amount: ton("9.99"), Comment: b.Comment, Timestamp: now.Unix()}
+		}},
+		{"wrong comment", func(b *Booking) *indexer.Transfer {
+			return &indexer.Transfer{EventID: "tx", Amount: ton("10"), Comment: "AD-00000000", Timestamp: now.Unix()}
+		}},
+		{"before booking", func(b *Booking) *indexer.Transfer {
+			return &indexer.Transfer{EventID: "tx", Amount: ton("10"), Comment: b.Comment, Timestamp: now.Add(-time.Minute).Unix()}
+		}},
+		{"after window", func(b *Booking) *indexer.Transfer {
+			return &indexer.Transfer{EventID: "tx", Amount: ton("10"), Comment: b.Comment, Timestamp: now.Add(PaymentWindow + time.Second).Unix()}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watcher, source, booking, paid := newTestWatcher(t, now)
+			source.transfers = []*indexer.Transfer{tt.transfer(booking)}
+
+			watcher.Check(now.Add(PaymentWindow + 2*time.Second))
+
+			if len(*paid) != 0 || len(watcher.Promo.Campaigns()) != 0 {
+				t.Errorf("booking activated by an invalid payment")
+			}
+		})
+	}
+}
+
+func TestWatcherExpiresUnpaidBooking(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	watcher, _, _, _ := newTestWatcher(t, now)
+
+	watcher.Check(now.Add(PaymentWindow))
+	if status := watcher.Bookings.List()[0].Status; status != StatusPending {
+		t.Fatalf("booking expired inside the grace period, status %s", status)
+	}
+
+	watcher.Check(now.Add(PaymentWindow + paymentGrace + time.Second))
+	if status := watcher.Bookings.List()[0].Status; status != StatusExpired {
+		t.Fatalf("expected expired booking, status %s", status)
+	}
+}
+
+func TestParseSlots(t *testing.T) {
+	slots, err := ParseSlots("3d=12.5, 1d=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(slots) != 2 || slots[0].Duration != 24*time.Hour || FormatTON(slots[1].Price) != "12.5" {
+		t.Errorf("unexpected slots %+v", slots)
+	}
+
+	for _, value := range []string{"", "1d", "1d=0", "1d=-1", "1d=0.0000000001", "x=5"} {
+		if _, err := ParseSlots(value); err == nil {
+			t.Errorf("ParseSlots(%q) should fail", value)
+		}
+	}
+}
@@ -8,22 +8,28 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/polarysfoundation/kilocompbot/bot/backups"
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
 	"github.com/polarysfoundation/kilocompbot/bot/commands"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
 	"github.com/polarysfoundation/kilocompbot/bot/notificator"
 	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/bot/sender"
+	"github.com/polarysfoundation/kilocompbot/config"
 	"github.com/polarysfoundation/kilocompbot/core"
 	"github.com/polarysfoundation/kilocompbot/groups"
+	"github.com/polarysfoundation/kilocompbot/indexer"
 )
 
 type Bot struct {
 	API     *tgbotapi.BotAPI
 	DB      *sql.DB
 	Context context.Context
-	TONAPI  string
+	// Config tiene las opciones leidas del archivo env, como la API de TON, las plantillas y los anuncios pagados
+	Config *config.Config
 }
 
-func InitBot(token string, db *sql.DB, ctx context.Context, tonAPI string) (*Bot, error) {
-	botAPI, err := tgbotapi.NewBotAPI(token)
+func InitBot(cfg *config.Config, db *sql.DB, ctx context.Context) (*Bot, error) {
+	botAPI, err := tgbotapi.NewBotAPI(cfg.BotToken)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +38,7 @@ func InitBot(token string, db *sql.DB, ctx context.Context, tonAPI string) (*Bot
 		API:     botAPI,
 		DB:      db,
 		Context: ctx,
-		TONAPI:  tonAPI,
+		Config:  cfg,
 	}, nil
 }
 
@@ -44,10 +50,14 @@ func (b *Bot) Run() {
 	groupsMap := groups.InitGroups()
 	temps := groups.InitTemp()
 	comps := core.InitComp()
+	exclusions := core.InitExclusions()
+	roles := core.InitRoles()
 	promo := promotions.InitParams()
 	events := notificator.InitEvents()
+	ads := bookings.InitBookings(b.Config.PaymentAddress, b.Config.AdSlots)
+	tracker := promotions.InitTracker(b.Config.TrackingURL, b.Config.TrackingAddr)
 
-	backup := backups.InitBackup(b.DB, groupsMap, comps, promo, temps)
+	backup := backups.InitBackup(b.DB, groupsMap, comps, promo, temps, exclusions, roles, ads, tracker)
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
@@ -57,15 +67,33 @@ func (b *Bot) Run() {
 		log.Println(err)
 	}
 
-	event := notificator.Init(b.API, groupsMap, comps, events, b.TONAPI, promo, b.DB)
+	queue := sender.Init(b.API)
+
+	texts, err := messages.Load(b.Config.TemplatesDir)
+	if err != nil {
+		log.Printf("no se pudieron cargar las plantillas de %s, se usan las incluidas: %v", b.Config.TemplatesDir, err)
+		texts, err = messages.Load("")
+		if err != nil {
+			log.Fatalf("error cargando las plantillas: %v", err)
+		}
+	}
+
+	event := notificator.Init(b.API, queue, texts, groupsMap, comps, events, b.Config.TONCenterAPI, promo, tracker, b.DB, exclusions)
 
 	backup.LoadData(event)
 
 	admins := commands.InitAdmins()
-	handler := commands.InitCommands(groupsMap, temps, comps, admins, b.API, promo, event)
+	handler := commands.InitCommands(groupsMap, temps, comps, admins, b.API, queue, texts, promo, event, exclusions, roles, ads, tracker, b.DB)
+	handler.LoadAdmins(b.Config.SuperAdmins)
+
+	// Al confirmarse un pago se guarda la campaña enseguida para no perder un anuncio ya cobrado
+	watcher := bookings.InitWatcher(ads, promo, &indexer.TonAPI{Key: b.Config.TONCenterAPI}, func(booking *bookings.Booking) {
+		backup.StoreCampaigns()
+		handler.BookingPaid(booking)
+	})
 
 	var wg sync.WaitGroup
-	wg.Add(3)
+	wg.Add(5)
 	go func(updates <-chan tgbotapi.Update) {
 		defer wg.Done()
 		handler.HandleGroup(updates)
@@ -81,6 +109,16 @@ func (b *Bot) Run() {
 		backup.HandleBackup()
 	}()
 
+	go func() {
+		defer wg.Done()
+		watcher.HandlePayments()
+	}()
+
+	go func() {
+		defer wg.Done()
+		tracker.HandleClicks(promo)
+	}()
+
 	// Esperar a que todas las goroutines terminen
 	wg.Wait()
 }
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+)
+
+const (
+	adStatsReport = "ad_stats"
+
+	// reportGroups es la cantidad de grupos que se muestran por campaña en el reporte
+	reportGroups = 5
+	// reportNameLength es el largo maximo del texto de la campaña en el reporte
+	reportNameLength = 40
+)
+
+// campaignReport son las impresiones y clicks de una campaña sumando todos los grupos. ID 0 es el anuncio por defecto.
+type campaignReport struct {
+	ID          int64
+	Name        string
+	Impressions int64
+	Clicks      int64
+	CTR         string
+	Groups      []promotions.AdStat
+	More        int
+}
+
+// adReport es el reporte del panel, Tracking indica si los clicks pasan por el redireccionador
+type adReport struct {
+	Tracking  bool
+	Campaigns []*campaignReport
+}
+
+func clickRate(impressions int64, clicks int64) string {
+	if impressions == 0 {
+		return "0%"
+	}
+
+	return fmt.Sprintf("%.2f%%", float64(clicks)*100/float64(impressions))
+}
+
+// campaignName acorta el texto de la campaña a su primera linea para el reporte
+func campaignName(text string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+
+	runes := []rune(name)
+	if len(runes) > reportNameLength {
+		return string(runes[:reportNameLength]) + "…"
+	}
+
+	return name
+}
+
+// adReport agrupa las estadisticas por campaña, las estadisticas ya vienen ordenadas por campaña y por impresiones
+func (c *Commands) adReport() *adReport {
+	names := make(map[int64]string)
+	for _, campaign := range c.promotions.Campaigns() {
+		names[campaign.ID] = campaignName(campaign.AdName)
+	}
+
+	report := &adReport{Tracking: c.tracker.BaseURL != ""}
+
+	var current *campaignReport
+	for _, stat := range c.tracker.Stats() {
+		if current == nil || current.ID != stat.CampaignID {
+			current = &campaignReport{ID: stat.CampaignID, Name: names[stat.CampaignID]}
+			report.Campaigns = append(report.Campaigns, current)
+		}
+
+		current.Impressions += stat.Impressions
+		current.Clicks += stat.Clicks
+
+		if len(current.Groups) < reportGroups {
+			current.Groups = append(current.Groups, stat)
+		} else {
+			current.More++
+		}
+	}
+
+	for _, campaign := range report.Campaigns {
+		campaign.CTR = clickRate(campaign.Impressions, campaign.Clicks)
+	}
+
+	return report
+}
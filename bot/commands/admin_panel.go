@@ -7,10 +7,16 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"strconv"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/getters"
 )
 
 const (
@@ -20,9 +26,12 @@ const (
 	change_button_content = "Change Button Link"
 	change_button_context = "Change Button Name"
 	exit                  = "Exit"
-	total_groups          = "Total Groups"
+	manage_groups         = "Manage Groups"
 	send_announcement     = "Send Announcement"
-	sending_announcement  = "Sending Announcement"
+	exclude_wallet        = "Exclude Wallet"
+	include_wallet        = "Include Wallet"
+	excluded_wallets      = "Excluded Wallets"
+	ad_stats              = "Ad Stats"
 )
 
 var (
@@ -33,206 +42,369 @@ var (
 	errCommandNotActive     = errors.New("error: commando ya esta desactivado")
 	errAdminAlreadyLogged   = errors.New("error: administrador ya esta loggueado")
 	errAdminNotLogged       = errors.New("error: administrador no esta loggueado")
+	errAdminSessionExpired  = errors.New("error: la sesion del administrador expiro")
 )
 
 const (
-	onlyAdmins         = "Sorry only administrators can use that command, please if you have a question you can contact admins at t.me/KiloTonCoin"
-	notGroups          = "Sorry, for this mode it is not allowed to be used in groups. "
-	errAdminNotAllowed = "Sorry, no action allowed, any questions you can contact a bot administrator. "
-	errAlreadyLogued   = "Sorry, but you're already on the admin panel."
-	errDoubleSession   = "There is already an administrator with an active session, please try later. "
-	errNoLoggued       = "I'm sorry, there's no active session. "
-
-	addNewText          = "Send new text"
-	addNewVideo         = "Send new video"
-	addNewButtonContent = "Send new button content"
-	addNewButtonContext = "Send new button context"
-	addAnnouncement     = "Send new accouncement"
+	onlyAdmins         = "only_admins"
+	notGroups          = "not_groups"
+	errAdminNotAllowed = "err_admin_not_allowed"
+	errAlreadyLogued   = "err_already_logued"
+	errSessionExpired  = "err_session_expired"
+	errNoLoggued       = "err_no_loggued"
+
+	addNewText          = "add_new_text"
+	addNewVideo         = "add_new_video"
+	addNewButtonContent = "add_new_button_content"
+	addNewButtonContext = "add_new_button_context"
+	addAnnouncement     = "add_announcement"
+	addExcludedWallet   = "add_excluded_wallet"
+	addIncludedWallet   = "add_included_wallet"
 
 	cancelMarkup = "Cancel"
 	cancel       = "cancel"
 
-	textUpdated          = "promo text updated. "
-	mediaUpdated         = "The ad video has already been updated."
-	buttonContextUpdated = "The contents of the button have been updated. "
-	buttonContentUpdated = "The button name have been updated. "
+	textUpdated          = "text_updated"
+	mediaUpdated         = "media_updated"
+	invalidMedia         = "invalid_media"
+	buttonContextUpdated = "button_context_updated"
+	buttonContentUpdated = "button_content_updated"
+	globalExcluded       = "global_excluded"
+	globalIncluded       = "global_included"
+	emptyGlobalExcluded  = "empty_global_excluded"
 )
 
+// sessionTimeout es el tiempo sin actividad tras el cual se cierra la sesion de un administrador
+const sessionTimeout = 30 * time.Minute
+
+const (
+	// RoleAdmin puede usar el panel de administracion
+	RoleAdmin = "admin"
+
+	// RoleSuperAdmin ademas puede agregar y quitar administradores. Solo se asigna desde la configuracion.
+	RoleSuperAdmin = "super"
+)
+
+// BotAdmin es un administrador del bot, se identifica por el id de Telegram porque el usuario puede cambiar
+type BotAdmin struct {
+	ID       int64
+	Username string
+	Role     string
+}
+
+// session es el estado del panel de un administrador, cada uno tiene su propia accion pendiente
+type session struct {
+	pending  string
+	lastSeen time.Time
+}
+
 type Admins struct {
-	AdminAllowed  map[string]struct{}
-	CommandActive map[string]bool
-	Signed        map[string]bool
-	Total         int64
-	mutex         sync.RWMutex
+	AdminAllowed map[int64]*BotAdmin
+	sessions     map[int64]*session
+	mutex        sync.RWMutex
 }
 
 func InitAdmins() *Admins {
 	return &Admins{
-		AdminAllowed:  make(map[string]struct{}),
-		CommandActive: make(map[string]bool),
-		Signed:        make(map[string]bool),
+		AdminAllowed: make(map[int64]*BotAdmin),
+		sessions:     make(map[int64]*session),
+	}
+}
+
+// current devuelve la sesion del administrador, si lleva mas de sessionTimeout sin actividad se descarta.
+// Se debe llamar con el mutex tomado.
+func (a *Admins) current(id int64) (*session, error) {
+	s, exist := a.sessions[id]
+	if !exist {
+		return nil, errAdminNotLogged
+	}
+
+	if time.Since(s.lastSeen) > sessionTimeout {
+		delete(a.sessions, id)
+		return nil, errAdminSessionExpired
 	}
+
+	return s, nil
 }
 
-func (a *Admins) IsLogged(username string) bool {
+func (a *Admins) IsLogged(id int64) bool {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if username == "" {
+	if id == 0 {
 		return false
 	}
 
-	if _, exist := a.AdminAllowed[username]; !exist {
+	if _, exist := a.AdminAllowed[id]; !exist {
 		return false
 	}
 
-	if _, exist := a.Signed[username]; exist {
-		return true
+	_, err := a.current(id)
+
+	return err == nil
+}
+
+// Touch renueva la sesion del administrador. Devuelve errAdminSessionExpired si se cerro por inactividad.
+func (a *Admins) Touch(id int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if id == 0 {
+		return errEmptyParam
 	}
 
-	return false
+	if _, exist := a.AdminAllowed[id]; !exist {
+		return errAdminNotExist
+	}
+
+	s, err := a.current(id)
+	if err != nil {
+		return err
+	}
+
+	s.lastSeen = time.Now()
+
+	return nil
 }
 
-func (a *Admins) SignIn(username string) error {
+func (a *Admins) SignIn(id int64) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if username == "" {
+	if id == 0 {
 		return errEmptyParam
 	}
 
-	if _, exist := a.AdminAllowed[username]; !exist {
+	if _, exist := a.AdminAllowed[id]; !exist {
 		return errAdminNotExist
 	}
 
-	if _, exist := a.Signed[username]; exist {
+	if _, err := a.current(id); err == nil {
 		return errAdminAlreadyLogged
 	}
 
-	a.Total = 1
-	a.Signed[username] = true
+	a.sessions[id] = &session{lastSeen: time.Now()}
 
 	return nil
 }
 
-func (a *Admins) SignOut(username string) error {
+func (a *Admins) SignOut(id int64) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if username == "" {
+	if id == 0 {
 		return errEmptyParam
 	}
 
-	if _, exist := a.AdminAllowed[username]; !exist {
+	if _, exist := a.AdminAllowed[id]; !exist {
 		return errAdminNotExist
 	}
 
-	if _, exist := a.Signed[username]; !exist {
+	if _, exist := a.sessions[id]; !exist {
 		return errAdminNotLogged
 	}
 
-	a.Total = 0
-	delete(a.Signed, username)
+	delete(a.sessions, id)
 
 	return nil
 }
 
-func (a *Admins) AdminExist(username string) bool {
+func (a *Admins) AdminExist(id int64) bool {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if username == "" {
+	if id == 0 {
 		return false
 	}
 
-	if _, exist := a.AdminAllowed[username]; exist {
+	if _, exist := a.AdminAllowed[id]; exist {
 		return true
 	}
 
 	return false
 }
 
-func (a *Admins) AddAdmin(username string) error {
+func (a *Admins) IsSuperAdmin(id int64) bool {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if username == "" {
+	admin, exist := a.AdminAllowed[id]
+	if !exist {
+		return false
+	}
+
+	return admin.Role == RoleSuperAdmin
+}
+
+// GetAdmin devuelve una copia del administrador
+func (a *Admins) GetAdmin(id int64) (BotAdmin, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	admin, exist := a.AdminAllowed[id]
+	if !exist {
+		return BotAdmin{}, errAdminNotExist
+	}
+
+	return *admin, nil
+}
+
+func (a *Admins) AddAdmin(id int64, username string, role string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if id == 0 || role == "" {
 		return errEmptyParam
 	}
 
-	if _, exist := a.AdminAllowed[username]; exist {
+	if _, exist := a.AdminAllowed[id]; exist {
 		return errAdminAlreadyExist
 	}
 
-	a.AdminAllowed[username] = struct{}{}
+	a.AdminAllowed[id] = &BotAdmin{ID: id, Username: username, Role: role}
 
 	return nil
 }
 
-func (a *Admins) RemoveAdmin(username string) error {
+// UpdateAdmin cambia el usuario y el rol de un administrador existente
+func (a *Admins) UpdateAdmin(id int64, username string, role string) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if username == "" {
+	admin, exist := a.AdminAllowed[id]
+	if !exist {
+		return errAdminNotExist
+	}
+
+	admin.Username = username
+	if role != "" {
+		admin.Role = role
+	}
+
+	return nil
+}
+
+func (a *Admins) RemoveAdmin(id int64) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if id == 0 {
 		return errEmptyParam
 	}
 
-	if _, exist := a.AdminAllowed[username]; !exist {
+	if _, exist := a.AdminAllowed[id]; !exist {
 		return errAdminNotExist
 	}
 
-	delete(a.AdminAllowed, username)
+	delete(a.AdminAllowed, id)
+	delete(a.sessions, id)
 
 	return nil
 }
 
-func (a *Admins) ActiveCommand(command string) error {
+// List devuelve los administradores, primero los super administradores y luego por id
+func (a *Admins) List() []BotAdmin {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	admins := make([]BotAdmin, 0, len(a.AdminAllowed))
+	for _, admin := range a.AdminAllowed {
+		admins = append(admins, *admin)
+	}
+
+	sort.Slice(admins, func(i, j int) bool {
+		if admins[i].Role != admins[j].Role {
+			return admins[i].Role == RoleSuperAdmin
+		}
+		return admins[i].ID < admins[j].ID
+	})
+
+	return admins
+}
+
+// ActiveCommand deja command como la accion pendiente del administrador, reemplazando la anterior
+func (a *Admins) ActiveCommand(id int64, command string) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if command == "" {
+	if id == 0 || command == "" {
 		return errEmptyParam
 	}
 
-	if _, exist := a.CommandActive[command]; exist {
+	s, err := a.current(id)
+	if err != nil {
+		return err
+	}
+
+	if s.pending == command {
 		return errCommandAlreadyActive
 	}
 
-	a.CommandActive[command] = true
+	s.pending = command
+	s.lastSeen = time.Now()
 
 	return nil
 }
 
-func (a *Admins) DeactivateCommand(command string) error {
+func (a *Admins) DeactivateCommand(id int64, command string) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if command == "" {
+	if id == 0 || command == "" {
 		return errEmptyParam
 	}
 
-	if _, exist := a.CommandActive[command]; !exist {
+	s, err := a.current(id)
+	if err != nil {
+		return err
+	}
+
+	if s.pending != command {
 		return errCommandNotActive
 	}
 
-	delete(a.CommandActive, command)
+	s.pending = ""
 
 	return nil
 }
 
-func (a *Admins) CommandStatus(command string) bool {
+// CancelCommand descarta la accion pendiente del administrador, sea cual sea
+func (a *Admins) CancelCommand(id int64) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	if command == "" {
+	if id == 0 {
+		return errEmptyParam
+	}
+
+	s, err := a.current(id)
+	if err != nil {
+		return err
+	}
+
+	if s.pending == "" {
+		return errCommandNotActive
+	}
+
+	s.pending = ""
+
+	return nil
+}
+
+func (a *Admins) CommandStatus(id int64, command string) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if id == 0 || command == "" {
 		return false
 	}
 
-	if _, exist := a.CommandActive[command]; exist {
-		return true
+	s, err := a.current(id)
+	if err != nil {
+		return false
 	}
 
-	return false
+	return s.pending == command
 }
 
 func (p *Commands) handleAdminCommands(update tgbotapi.Update) {
@@ -247,6 +419,7 @@ func (p *Commands) handleAdminCommands(update tgbotapi.Update) {
 
 	if update.Message.IsCommand() {
 		userName := update.Message.From.UserName
+		userID := int64(update.Message.From.ID)
 
 		switch update.Message.Command() {
 		case admin:
@@ -256,36 +429,41 @@ func (p *Commands) handleAdminCommands(update tgbotapi.Update) {
 
 			if chat.IsGroup() && chat.IsSuperGroup() || chat.IsSuperGroup() || chat.IsGroup() {
 				log.Printf("the current group %v, es un grupo o un supergrupo", chatID)
-				p.send(chatID, notGroups)
+				p.reply(chatID, notGroups, nil)
 				return
 			}
 
-			if !p.Admins.AdminExist(userName) {
+			if !p.Admins.AdminExist(userID) {
 				log.Printf("el usuario %s no es un administrador del bot", userName)
-				p.send(chatID, errAdminNotAllowed)
+				p.reply(chatID, errAdminNotAllowed, nil)
 				return
 			}
 
-			if p.Admins.IsLogged(userName) {
+			if p.Admins.IsLogged(userID) {
 				log.Printf("el usuario %s ya esta logueado", userName)
-				p.send(chatID, errAlreadyLogued)
-				return
-			}
-
-			if p.Admins.Total == 1 {
-				log.Print("Ya hay un usuario logueado")
-				p.send(chatID, errDoubleSession)
+				p.reply(chatID, errAlreadyLogued, nil)
 				return
 			}
 
-			err := p.Admins.SignIn(userName)
+			err := p.Admins.SignIn(userID)
 			if err != nil {
 				log.Printf("Error mientras se iniciaba sesion con el usuario %s", userName)
 				return
 			}
 
+			p.refreshAdmin(update.Message.From)
+			p.audit(update.Message, core.GlobalScope, "login", "")
 			p.sendOptions(chatID)
 			return
+		case addadmin, removeadmin, listAdmins:
+			p.handleRoster(update)
+			return
+		case addcampaign, removecampaign, listCampaigns:
+			p.handleCampaigns(update)
+			return
+		case audit:
+			p.globalAudit(update.Message)
+			return
 		default:
 			return
 		}
@@ -309,225 +487,268 @@ func (p *Commands) handleAdminsNoCommands(update tgbotapi.Update) {
 
 	if !update.Message.IsCommand() && chat.IsPrivate() {
 		userName := update.Message.From.UserName
+		userID := int64(update.Message.From.ID)
 
 		param := update.Message.Text
 
-		if p.Admins.Total == 1 && p.Admins.IsLogged(userName) {
+		err := p.Admins.Touch(userID)
+		if errors.Is(err, errAdminSessionExpired) {
+			log.Printf("la sesion del usuario %s expiro por inactividad", userName)
+			p.hideKeyboard(chatID, errSessionExpired)
+			return
+		}
+
+		if err == nil {
 			if chat.IsGroup() && chat.IsSuperGroup() {
 				log.Printf("the current group %v, es un grupo o un supergrupo", chatID)
-				p.send(chatID, notGroups)
+				p.reply(chatID, notGroups, nil)
 				return
 			}
 
-			if !p.Admins.AdminExist(userName) {
+			if !p.Admins.AdminExist(userID) {
 				log.Printf("el usuario %s no es un administrador del bot", userName)
-				p.send(chatID, errAdminNotAllowed)
+				p.reply(chatID, errAdminNotAllowed, nil)
 				return
 			}
 
-			if p.Admins.CommandStatus(change_text) {
+			if p.Admins.CommandStatus(userID, change_text) {
 				err := p.promotions.UpdateAdName(param)
 				if err != nil {
 					log.Printf("no se pudo actualizar el texto del usuario %s", userName)
 					return
 				}
 
-				err = p.Admins.DeactivateCommand(change_text)
+				err = p.Admins.DeactivateCommand(userID, change_text)
 				if err != nil {
 					log.Print("error mientras se cerraba session y desactivaba el comando")
 					return
 				}
 
-				p.send(chatID, textUpdated)
+				p.audit(update.Message, core.GlobalScope, "changetext", param)
+				p.reply(chatID, textUpdated, nil)
 				return
 			}
 
-			if p.Admins.CommandStatus(change_video) {
-				if update.Message.Video != nil {
+			if p.Admins.CommandStatus(userID, change_video) {
+				// Telegram envia los GIF como animacion y ademas como documento, por eso se revisa primero Animation
+				switch {
+				case update.Message.Animation != nil:
+					fileID := update.Message.Animation.FileID
+					p.saveMedia(fileID, fmt.Sprintf("%s.mp4", fileID), promotions.MediaAnimation)
+				case update.Message.Video != nil:
 					fileID := update.Message.Video.FileID
-					fileName := fmt.Sprintf("%s.mp4", fileID)
-					p.saveVideo(fileID, fileName)
+					p.saveMedia(fileID, fmt.Sprintf("%s.mp4", fileID), promotions.MediaVideo)
+				case update.Message.Photo != nil && len(*update.Message.Photo) > 0:
+					photos := *update.Message.Photo
+					fileID := photos[len(photos)-1].FileID
+					p.saveMedia(fileID, fmt.Sprintf("%s.jpg", fileID), promotions.MediaPhoto)
+				case strings.EqualFold(strings.TrimSpace(param), promotions.MediaNone):
+					p.promotions.DisableMedia()
+				default:
+					p.reply(chatID, invalidMedia, nil)
+					return
 				}
 
-				err = p.Admins.DeactivateCommand(change_video)
+				err = p.Admins.DeactivateCommand(userID, change_video)
 				if err != nil {
 					log.Print("error mientras se cerraba session y desactivaba el comando")
 					return
 				}
 
-				p.send(chatID, mediaUpdated)
+				p.audit(update.Message, core.GlobalScope, "changevideo", "")
+				p.reply(chatID, mediaUpdated, nil)
 				return
 			}
 
-			if p.Admins.CommandStatus(change_button_context) {
+			if p.Admins.CommandStatus(userID, change_button_context) {
 				err := p.promotions.UpdateButtonName(param)
 				if err != nil {
 					log.Printf("no se pudo actualizar el texto del usuario %s", userName)
 					return
 				}
 
-				err = p.Admins.DeactivateCommand(change_button_context)
+				err = p.Admins.DeactivateCommand(userID, change_button_context)
 				if err != nil {
 					log.Print("error mientras se cerraba session y desactivaba el comando")
 					return
 				}
 
-				p.send(chatID, buttonContextUpdated)
+				p.audit(update.Message, core.GlobalScope, "changebuttonname", param)
+				p.reply(chatID, buttonContextUpdated, nil)
 				return
 			}
 
-			if p.Admins.CommandStatus(change_button_content) {
+			if p.Admins.CommandStatus(userID, change_button_content) {
 				err := p.promotions.UpdateButtonLink(param)
 				if err != nil {
 					log.Printf("no se pudo actualizar el texto del usuario %s", userName)
 					return
 				}
 
-				err = p.Admins.DeactivateCommand(change_button_content)
+				err = p.Admins.DeactivateCommand(userID, change_button_content)
 				if err != nil {
 					log.Print("error mientras se cerraba session y desactivaba el comando")
 					return
 				}
 
-				p.send(chatID, buttonContentUpdated)
+				p.audit(update.Message, core.GlobalScope, "changebuttonlink", param)
+				p.reply(chatID, buttonContentUpdated, nil)
 				return
 			}
 
-			if p.Admins.CommandStatus(send_announcement) {
-				msg := param
-				for i := range p.Groups.ActiveGroups {
-					chatIDInt, _ := strconv.Atoi(i)
-					p.send(int64(chatIDInt), msg)
-					break
+			if p.Admins.CommandStatus(userID, exclude_wallet) || p.Admins.CommandStatus(userID, include_wallet) {
+				including := p.Admins.CommandStatus(userID, include_wallet)
+
+				wallet, err := getters.NormalizeAddress(param)
+				if err != nil {
+					log.Printf("direccion invalida enviada por el usuario %s: %v", userName, err)
+					p.reply(chatID, invalidWallet, nil)
+					return
+				}
+
+				if including {
+					err = p.Exclusions.Remove(core.GlobalScope, wallet)
+					if err != nil {
+						log.Printf("no se pudo remover la exclusion global: %v", err)
+						p.reply(chatID, errWalletNotExcluded, nil)
+					} else {
+						p.audit(update.Message, core.GlobalScope, "globalinclude", wallet)
+						p.reply(chatID, globalIncluded, nil)
+					}
+
+					err = p.Admins.DeactivateCommand(userID, include_wallet)
+					if err != nil {
+						log.Print("error mientras se cerraba session y desactivaba el comando")
+					}
+					return
 				}
 
-				err = p.Admins.DeactivateCommand(send_announcement)
+				err = p.Exclusions.Add(core.GlobalScope, wallet)
+				if err != nil {
+					log.Printf("no se pudo agregar la exclusion global: %v", err)
+					p.reply(chatID, errWalletExcluded, nil)
+				} else {
+					for _, id := range p.Groups.IDs() {
+						if p.Comps.CompExist(id) {
+							purchases, err := p.Comps.GetComp(id)
+							if err == nil {
+								purchases.RemovePurchasesByBuyer(wallet)
+							}
+						}
+					}
+
+					p.audit(update.Message, core.GlobalScope, "globalexclude", wallet)
+					p.reply(chatID, globalExcluded, nil)
+				}
+
+				err = p.Admins.DeactivateCommand(userID, exclude_wallet)
 				if err != nil {
 					log.Print("error mientras se cerraba session y desactivaba el comando")
-					return
 				}
+				return
+			}
 
-				p.send(chatID, sending_announcement)
+			if p.Admins.CommandStatus(userID, send_announcement) {
+				p.broadcastContent(update.Message)
+				return
+			}
+
+			if p.Admins.CommandStatus(userID, broadcastTarget) {
+				p.broadcastIDs(chatID, userID, param)
 				return
 			}
 
 			switch param {
 			case change_text:
-				err := p.Admins.ActiveCommand(change_text)
+				err := p.Admins.ActiveCommand(userID, change_text)
 				if err != nil {
 					log.Printf("error mientras se activaba el comando %s", change_text)
 					return
 				}
 
 				markup := p.keyboardMarkup(cancel, cancelMarkup)
-				p.sendReplyWithMarkup(chatID, addNewText, markup)
+				p.sendReplyWithMarkup(chatID, p.render(chatID, addNewText, nil), markup)
 				return
 			case change_video:
-				err := p.Admins.ActiveCommand(change_video)
+				err := p.Admins.ActiveCommand(userID, change_video)
 				if err != nil {
 					log.Printf("error mientras se activaba el comando %s", change_video)
 					return
 				}
 
 				markup := p.keyboardMarkup(cancel, cancelMarkup)
-				p.sendReplyWithMarkup(chatID, addNewVideo, markup)
+				p.sendReplyWithMarkup(chatID, p.render(chatID, addNewVideo, nil), markup)
 				return
 			case change_button_content:
-				err := p.Admins.ActiveCommand(change_button_content)
+				err := p.Admins.ActiveCommand(userID, change_button_content)
 				if err != nil {
 					log.Printf("error mientras se activaba el comando %s", change_button_content)
 					return
 				}
 
 				markup := p.keyboardMarkup(cancel, cancelMarkup)
-				p.sendReplyWithMarkup(chatID, addNewButtonContent, markup)
+				p.sendReplyWithMarkup(chatID, p.render(chatID, addNewButtonContent, nil), markup)
 				return
 			case change_button_context:
-				err := p.Admins.ActiveCommand(change_button_context)
+				err := p.Admins.ActiveCommand(userID, change_button_context)
 				if err != nil {
 					log.Printf("error mientras se activaba el comando %s", change_button_context)
 					return
 				}
 
 				markup := p.keyboardMarkup(cancel, cancelMarkup)
-				p.sendReplyWithMarkup(chatID, addNewButtonContext, markup)
+				p.sendReplyWithMarkup(chatID, p.render(chatID, addNewButtonContext, nil), markup)
 				return
-			case exit:
-
-				status := p.Admins.CommandStatus(change_text)
-				if status {
-					err = p.Admins.DeactivateCommand(change_text)
-					if err != nil {
-						log.Print("error mientras se cerraba session y desactivaba el comando")
-						return
-					}
-				}
-
-				status = p.Admins.CommandStatus(change_video)
-				if status {
-					err = p.Admins.DeactivateCommand(change_video)
-					if err != nil {
-						log.Print("error mientras se cerraba session y desactivaba el comando")
-						return
-					}
-
-				}
-
-				status = p.Admins.CommandStatus(change_button_content)
-				if status {
-					err = p.Admins.DeactivateCommand(change_button_content)
-					if err != nil {
-						log.Print("error mientras se cerraba session y desactivaba el comando")
-						return
-					}
+			case exclude_wallet, include_wallet:
+				err := p.Admins.ActiveCommand(userID, param)
+				if err != nil {
+					log.Printf("error mientras se activaba el comando %s", param)
+					return
 				}
 
-				status = p.Admins.CommandStatus(change_button_context)
-				if status {
-					err = p.Admins.DeactivateCommand(change_button_context)
-					if err != nil {
-						log.Print("error mientras se cerraba session y desactivaba el comando")
-						return
-					}
+				text := addExcludedWallet
+				if param == include_wallet {
+					text = addIncludedWallet
 				}
 
-				status = p.Admins.CommandStatus(send_announcement)
-				if status {
-					err = p.Admins.DeactivateCommand(send_announcement)
-					if err != nil {
-						log.Print("error mientras se cerraba session y desactivaba el comando")
-						return
-					}
+				markup := p.keyboardMarkup(cancel, cancelMarkup)
+				p.sendReplyWithMarkup(chatID, p.render(chatID, text, nil), markup)
+				return
+			case excluded_wallets:
+				wallets := p.Exclusions.List(core.GlobalScope)
+				if len(wallets) == 0 {
+					p.reply(chatID, emptyGlobalExcluded, nil)
+					return
 				}
 
-				err := p.Admins.SignOut(userName)
+				p.reply(chatID, "global_excluded_wallets", wallets)
+				return
+			case ad_stats:
+				p.reply(chatID, adStatsReport, p.adReport())
+				return
+			case exit:
+				err := p.Admins.SignOut(userID)
 				if err != nil {
 					log.Print("error mientras se cerraba session")
 					return
 				}
 
-				p.hideKeyboard(chatID)
+				p.audit(update.Message, core.GlobalScope, "logout", "")
+				p.hideKeyboard(chatID, "admin_exit")
 
 				return
-			case total_groups:
-				totalGroups := make([]string, 0)
-				for i := range p.Groups.ActiveGroups {
-					totalGroups = append(totalGroups, i)
-					break
-				}
-
-				msg := fmt.Sprintf("Total groups: %d", len(totalGroups))
-				p.send(chatID, msg)
+			case manage_groups:
+				p.sendGroups(chatID)
 				return
 			case send_announcement:
-				err := p.Admins.ActiveCommand(send_announcement)
+				err := p.Admins.ActiveCommand(userID, send_announcement)
 				if err != nil {
 					log.Printf("error mientras se activaba el comando %s", change_button_context)
 					return
 				}
 
 				markup := p.keyboardMarkup(cancel, cancelMarkup)
-				p.sendReplyWithMarkup(chatID, addAnnouncement, markup)
+				p.sendReplyWithMarkup(chatID, p.render(chatID, addAnnouncement, nil), markup)
 				return
 			default:
 				return
@@ -542,23 +763,27 @@ func (p *Commands) handleAdminsNoCommands(update tgbotapi.Update) {
 
 func (p *Commands) send(chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
-	_, err := p.BotAPI.Send(msg)
-	if err != nil {
-		log.Println("Error al enviar mensaje:", err)
-	}
+	msg.ParseMode = messages.ParseMode
+	p.Sender.Send(chatID, msg)
+}
+
+// reply envia el mensaje name generado con las plantillas del bot
+func (p *Commands) reply(chatID int64, name string, data interface{}) {
+	p.send(chatID, p.render(chatID, name, data))
+}
+
+// render genera el mensaje name en el idioma del chat
+func (p *Commands) render(chatID int64, name string, data interface{}) string {
+	return p.Messages.RenderIn(p.locale(chatID), name, data)
 }
 
 func (p *Commands) sendReplyWithMarkup(chatID int64, text string, markup *tgbotapi.InlineKeyboardMarkup) {
 	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
+	msg.ParseMode = messages.ParseMode
 	if markup != nil {
 		msg.ReplyMarkup = markup
 	}
-	_, err := p.BotAPI.Send(msg)
-	if err != nil {
-		log.Println("Error al enviar mensaje:", err)
-	}
+	p.Sender.Send(chatID, msg)
 }
 
 func (a *Commands) keyboardMarkup(buttonContext string, buttonContent string) *tgbotapi.InlineKeyboardMarkup {
@@ -569,46 +794,50 @@ func (a *Commands) keyboardMarkup(buttonContext string, buttonContent string) *t
 }
 
 func (p *Commands) sendOptions(chatID int64) {
-	msg := tgbotapi.NewMessage(chatID, "Select an option below")
+	msg := tgbotapi.NewMessage(chatID, p.render(chatID, "admin_options", nil))
+	msg.ParseMode = messages.ParseMode
 
 	// Crear los botones del teclado personalizado
 	button1 := tgbotapi.NewKeyboardButton(change_video)
 	button2 := tgbotapi.NewKeyboardButton(change_text)
 	button3 := tgbotapi.NewKeyboardButton(change_button_context)
 	button4 := tgbotapi.NewKeyboardButton(change_button_content)
-	button5 := tgbotapi.NewKeyboardButton(total_groups)
+	button5 := tgbotapi.NewKeyboardButton(manage_groups)
 	button6 := tgbotapi.NewKeyboardButton(send_announcement)
 	button7 := tgbotapi.NewKeyboardButton(exit)
+	button8 := tgbotapi.NewKeyboardButton(exclude_wallet)
+	button9 := tgbotapi.NewKeyboardButton(include_wallet)
+	button10 := tgbotapi.NewKeyboardButton(excluded_wallets)
+	button11 := tgbotapi.NewKeyboardButton(ad_stats)
 
 	// Crear las filas de botones
 	row1 := tgbotapi.NewKeyboardButtonRow(button1, button2)
 	row2 := tgbotapi.NewKeyboardButtonRow(button3, button4)
-	row3 := tgbotapi.NewKeyboardButtonRow(button5, button6)
-	row4 := tgbotapi.NewKeyboardButtonRow(button7)
+	row3 := tgbotapi.NewKeyboardButtonRow(button5, button6, button11)
+	row4 := tgbotapi.NewKeyboardButtonRow(button8, button9, button10)
+	row5 := tgbotapi.NewKeyboardButtonRow(button7)
 
 	// Crear el teclado personalizado con las filas de botones
-	replyKeyboard := tgbotapi.NewReplyKeyboard(row1, row2, row3, row4)
+	replyKeyboard := tgbotapi.NewReplyKeyboard(row1, row2, row3, row4, row5)
 	msg.ReplyMarkup = replyKeyboard
 
-	p.BotAPI.Send(msg)
+	p.Sender.Send(chatID, msg)
 }
 
-func (p *Commands) hideKeyboard(chatID int64) {
+func (p *Commands) hideKeyboard(chatID int64, name string) {
 	// Crear una estructura ReplyKeyboardRemove
 	removeKeyboard := tgbotapi.NewRemoveKeyboard(true) // `true` para eliminar el teclado para todos los usuarios de este chat
 
 	// Crear un mensaje con la estructura ReplyKeyboardRemove
-	msg := tgbotapi.NewMessage(chatID, "leaving the administration panel")
+	msg := tgbotapi.NewMessage(chatID, p.render(chatID, name, nil))
+	msg.ParseMode = messages.ParseMode
 	msg.ReplyMarkup = removeKeyboard
 
 	// Enviar el mensaje
-	_, err := p.BotAPI.Send(msg)
-	if err != nil {
-		log.Println("Error al enviar el mensaje:", err)
-	}
+	p.Sender.Send(chatID, msg)
 }
 
-func (c *Commands) saveVideo(fileID string, fileName string) {
+func (c *Commands) saveMedia(fileID string, fileName string, mediaType string) {
 	file, err := c.BotAPI.GetFile(tgbotapi.FileConfig{FileID: fileID})
 	if err != nil {
 		log.Println("Error al obtener el archivo:", err)
@@ -617,12 +846,15 @@ func (c *Commands) saveVideo(fileID string, fileName string) {
 
 	filePath := fmt.Sprintf("assets/media/%s", fileName)
 
-	err = c.promotions.UpdateMedia(fileName)
+	err = c.promotions.UpdateMedia(fileName, mediaType)
 	if err != nil {
-		log.Printf("no se pudo actualizar el video por el siguiente error %v", err)
+		log.Printf("no se pudo actualizar la media por el siguiente error %v", err)
 		return
 	}
 
+	// El file_id del mensaje del admin se usa directamente, el archivo local queda como respaldo
+	c.promotions.CacheMediaFileID(filePath, fileID)
+
 	// URL completa para descargar el archivo
 	fileURL := file.Link(c.BotAPI.Token)
 
@@ -648,5 +880,5 @@ func (c *Commands) saveVideo(fileID string, fileName string) {
 		log.Println("Error al guardar el archivo local:", err)
 	}
 
-	log.Println("Video guardado como:", filePath)
+	log.Println("Media guardada como:", filePath)
 }
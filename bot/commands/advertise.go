@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
+	"github.com/polarysfoundation/kilocompbot/database"
+)
+
+const (
+	advertise = "advertise"
+
+	// bookPrefix es el prefijo del callback de los botones de duracion, seguido del indice del slot
+	bookPrefix = "book:"
+
+	usageAdvertise       = "usage_advertise"
+	errAdvertiseDisabled = "err_advertise_disabled"
+	errInvalidAdvert     = "err_invalid_advert"
+	errBookingFailed     = "err_booking_failed"
+	advertSlots          = "advert_slots"
+	advertSlotButton     = "advert_slot_button"
+	bookingCreated       = "booking_created"
+	bookingPaid          = "booking_paid"
+)
+
+// slotOption es una duracion de anuncio con su precio ya formateado en TON
+type slotOption struct {
+	Duration time.Duration
+	Price    string
+}
+
+// bookingPayment son los datos que necesita el anunciante para pagar su reserva
+type bookingPayment struct {
+	Address  string
+	Price    string
+	Comment  string
+	Link     string
+	Duration time.Duration
+	Expires  int64
+}
+
+// bookingActivated es el aviso al anunciante cuando se confirma su pago
+type bookingActivated struct {
+	Comment string
+	End     int64
+}
+
+// paymentLink arma el enlace de Tonkeeper que completa la direccion, el monto y el comentario del pago
+func paymentLink(booking *bookings.Booking, address string) string {
+	return fmt.Sprintf("https://app.tonkeeper.com/transfer/%s?amount=%s&text=%s", url.PathEscape(address), booking.Price.String(), url.QueryEscape(booking.Comment))
+}
+
+func (c *Commands) handleAdvertise(update tgbotapi.Update) {
+	message := update.Message
+	chatID := message.Chat.ID
+	userID := int64(message.From.ID)
+
+	if !message.Chat.IsPrivate() {
+		c.reply(chatID, notGroups, nil)
+		return
+	}
+
+	if !c.bookings.Enabled() {
+		c.reply(chatID, errAdvertiseDisabled, nil)
+		return
+	}
+
+	arguments := strings.TrimSpace(message.CommandArguments())
+	if arguments == "" {
+		c.reply(chatID, usageAdvertise, nil)
+		return
+	}
+
+	ad, _, err := parseAdFields(arguments, time.Now(), advertFields)
+	if err != nil {
+		log.Printf("anuncio invalido enviado por el usuario %d: %v", userID, err)
+		c.reply(chatID, errInvalidAdvert, nil)
+		return
+	}
+
+	if fileID, mediaType := messageMedia(message.ReplyToMessage); fileID != "" {
+		ad.MediaFileID = fileID
+		ad.MediaType = mediaType
+	}
+
+	c.bookings.SetDraft(userID, ad)
+
+	// Un boton por cada duracion, el callback lleva el indice del slot elegido
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(c.bookings.Slots))
+	for i, slot := range c.bookings.Slots {
+		label := c.render(chatID, advertSlotButton, &slotOption{Duration: slot.Duration, Price: bookings.FormatTON(slot.Price)})
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, bookPrefix+strconv.Itoa(i))))
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	c.sendReplyWithMarkup(chatID, c.render(chatID, advertSlots, &ad), &markup)
+}
+
+// handleBooking crea la reserva del anuncio pendiente con la duracion del boton presionado
+func (c *Commands) handleBooking(callbackQuery *tgbotapi.CallbackQuery) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Chat.ID
+	userID := int64(callbackQuery.From.ID)
+
+	slot, err := strconv.Atoi(strings.TrimPrefix(callbackQuery.Data, bookPrefix))
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	booking, err := c.bookings.Book(userID, chatID, slot, now)
+	if err != nil {
+		log.Printf("no se pudo crear la reserva del usuario %d: %v", userID, err)
+		c.reply(chatID, errBookingFailed, nil)
+		return
+	}
+
+	if c.DB != nil {
+		err = database.WriteBooking(c.DB, booking)
+		if err != nil {
+			log.Printf("no se pudo guardar la reserva %s: %v", booking.Comment, err)
+		}
+	}
+
+	log.Printf("reserva %s creada por el usuario %d por %s TON", booking.Comment, userID, bookings.FormatTON(booking.Price))
+
+	c.reply(chatID, bookingCreated, &bookingPayment{
+		Address:  c.bookings.Address,
+		Price:    bookings.FormatTON(booking.Price),
+		Comment:  booking.Comment,
+		Link:     paymentLink(booking, c.bookings.Address),
+		Duration: booking.Duration,
+		Expires:  booking.Expires,
+	})
+}
+
+// BookingPaid guarda la reserva pagada y avisa al anunciante que su anuncio ya esta activo
+func (c *Commands) BookingPaid(booking *bookings.Booking) {
+	if c.DB != nil {
+		err := database.WriteBooking(c.DB, booking)
+		if err != nil {
+			log.Printf("no se pudo guardar la reserva pagada %s: %v", booking.Comment, err)
+		}
+	}
+
+	c.reply(booking.ChatID, bookingPaid, &bookingActivated{
+		Comment: booking.Comment,
+		End:     booking.CampaignEnd,
+	})
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/database"
+)
+
+const (
+	audit = "audit"
+
+	auditLimit = 15
+
+	emptyAudit       = "empty_audit"
+	emptyGlobalAudit = "empty_global_audit"
+)
+
+// auditLine es una accion del registro, la plantilla escapa los valores introducidos por usuarios
+type auditLine struct {
+	Timestamp int64
+	Actor     string
+	Action    string
+	Params    string
+}
+
+// audit registra una accion administrativa, las acciones del panel de admins usan core.GlobalScope como grupo
+func (c *Commands) audit(message *tgbotapi.Message, groupID string, action string, params string) {
+	if c.DB == nil || message == nil || message.From == nil {
+		return
+	}
+
+	entry := &database.AuditEntry{
+		ActorID:       int64(message.From.ID),
+		ActorUsername: message.From.UserName,
+		GroupID:       groupID,
+		Action:        action,
+		Params:        params,
+		Timestamp:     time.Now().Unix(),
+	}
+
+	err := database.WriteAudit(c.DB, entry)
+	if err != nil {
+		log.Printf("no se pudo registrar la accion %s del usuario %d: %v", action, entry.ActorID, err)
+	}
+}
+
+func (c *Commands) auditMessage(chatID int64, groupID string) string {
+	entries, err := database.GetAudit(c.DB, groupID, auditLimit)
+	if err != nil {
+		log.Printf("no se pudo obtener el registro de auditoria del grupo %s: %v", groupID, err)
+		return c.render(chatID, errorUnexpected, nil)
+	}
+
+	if len(entries) == 0 {
+		if groupID == core.GlobalScope {
+			return c.render(chatID, emptyGlobalAudit, nil)
+		}
+		return c.render(chatID, emptyAudit, nil)
+	}
+
+	lines := make([]*auditLine, 0, len(entries))
+
+	for _, entry := range entries {
+		actor := fmt.Sprintf("%d", entry.ActorID)
+		if entry.ActorUsername != "" {
+			actor = "@" + entry.ActorUsername
+		}
+
+		lines = append(lines, &auditLine{
+			Timestamp: entry.Timestamp,
+			Actor:     actor,
+			Action:    entry.Action,
+			Params:    entry.Params,
+		})
+	}
+
+	return c.render(chatID, "audit_log", lines)
+}
+
+// globalAudit muestra a los administradores del bot el registro de las acciones del panel,
+// o el de un grupo si se envia /audit <chat id>
+func (c *Commands) globalAudit(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if !message.Chat.IsPrivate() {
+		return
+	}
+
+	if !c.Admins.AdminExist(int64(message.From.ID)) {
+		log.Printf("el usuario %s no es un administrador del bot", message.From.UserName)
+		c.reply(chatID, errAdminNotAllowed, nil)
+		return
+	}
+
+	groupID := strings.TrimSpace(message.CommandArguments())
+	if groupID == "" {
+		groupID = core.GlobalScope
+	}
+
+	c.send(chatID, c.auditMessage(chatID, groupID))
+}
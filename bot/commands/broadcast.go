@@ -0,0 +1,372 @@
+package commands
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+const (
+	// broadcastTarget y broadcastConfirm son los pasos del anuncio despues de recibir el mensaje
+	broadcastTarget  = "broadcast_target"
+	broadcastConfirm = "broadcast_confirm"
+
+	// broadcastPrefix es el prefijo de los callbacks del anuncio
+	broadcastPrefix = "bcast:"
+	targetAll       = broadcastPrefix + "all"
+	targetActive    = broadcastPrefix + "active"
+	broadcastSend   = broadcastPrefix + "send"
+
+	// broadcastInterval separa los envios del anuncio para no ocupar el limite global que usan las alertas de compra
+	broadcastInterval = 200 * time.Millisecond
+	// progressEvery es cada cuantos grupos se actualiza el mensaje de progreso
+	progressEvery = 10
+	// reportFailures es la cantidad de fallos que se listan en el reporte
+	reportFailures = 30
+	// captionLimit es el largo maximo que Telegram admite en el texto de una media
+	captionLimit = 1024
+
+	broadcastPreview      = "broadcast_preview"
+	broadcastTargets      = "broadcast_targets"
+	broadcastReady        = "broadcast_ready"
+	broadcastProgress     = "broadcast_progress"
+	broadcastReport       = "broadcast_report"
+	broadcastButtonAll    = "broadcast_button_all"
+	broadcastButtonActive = "broadcast_button_active"
+	broadcastButtonSend   = "broadcast_button_send"
+	errBroadcastEmpty     = "err_broadcast_empty"
+	errBroadcastCaption   = "err_broadcast_caption"
+	errBroadcastTargets   = "err_broadcast_targets"
+	errBroadcastExpired   = "err_broadcast_expired"
+
+	// Motivos de los envios fallidos del reporte
+	failureKicked   = "kicked"
+	failureNotFound = "not_found"
+	failureNoRights = "no_rights"
+	failureMigrated = "migrated"
+	failureOther    = "other"
+)
+
+// broadcast es el anuncio que prepara un administrador, Targets se completa al elegir los grupos
+type broadcast struct {
+	Text      string
+	FileID    string
+	MediaType string
+	Targets   []string
+	Ignored   int
+}
+
+type broadcasts struct {
+	drafts map[int64]*broadcast
+	mutex  sync.Mutex
+}
+
+func initBroadcasts() *broadcasts {
+	return &broadcasts{drafts: make(map[int64]*broadcast)}
+}
+
+func (b *broadcasts) set(userID int64, draft *broadcast) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.drafts[userID] = draft
+}
+
+func (b *broadcasts) get(userID int64) (*broadcast, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	draft, exist := b.drafts[userID]
+	return draft, exist
+}
+
+// take devuelve el anuncio y lo descarta, para que no se pueda enviar dos veces
+func (b *broadcasts) take(userID int64) (*broadcast, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	draft, exist := b.drafts[userID]
+	delete(b.drafts, userID)
+	return draft, exist
+}
+
+// broadcastFailure es un grupo al que no se pudo enviar el anuncio
+type broadcastFailure struct {
+	GroupID string
+	Reason  string
+	Detail  string
+}
+
+// broadcastResult son los datos del progreso y del reporte final
+type broadcastResult struct {
+	Total     int
+	Sent      int
+	Delivered int
+	Failures  []*broadcastFailure
+	More      int
+}
+
+// failureReason clasifica el error de Telegram para el reporte
+func failureReason(err error) (string, string) {
+	apiErr, ok := err.(tgbotapi.Error)
+	if !ok {
+		return failureOther, err.Error()
+	}
+
+	message := strings.ToLower(apiErr.Message)
+
+	switch {
+	case strings.Contains(message, "kicked"), strings.Contains(message, "not a member"):
+		return failureKicked, ""
+	case strings.Contains(message, "chat not found"):
+		return failureNotFound, ""
+	case strings.Contains(message, "not enough rights"), strings.Contains(message, "have no rights"), strings.Contains(message, "chat_write_forbidden"):
+		return failureNoRights, ""
+	case apiErr.MigrateToChatID != 0, strings.Contains(message, "upgraded to a supergroup"):
+		return failureMigrated, ""
+	default:
+		return failureOther, apiErr.Message
+	}
+}
+
+// broadcastMessage arma el anuncio para un chat, con su media si tiene
+func broadcastMessage(chatID int64, draft *broadcast) tgbotapi.Chattable {
+	text := messages.Escape(draft.Text)
+
+	switch draft.MediaType {
+	case promotions.MediaPhoto:
+		msg := tgbotapi.NewPhotoShare(chatID, draft.FileID)
+		msg.Caption = text
+		msg.ParseMode = messages.ParseMode
+		return msg
+	case promotions.MediaAnimation:
+		msg := tgbotapi.NewAnimationShare(chatID, draft.FileID)
+		msg.Caption = text
+		msg.ParseMode = messages.ParseMode
+		return msg
+	case promotions.MediaVideo:
+		msg := tgbotapi.NewVideoShare(chatID, draft.FileID)
+		msg.Caption = text
+		msg.ParseMode = messages.ParseMode
+		return msg
+	default:
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = messages.ParseMode
+		return msg
+	}
+}
+
+// activeTargets devuelve los grupos con una competencia en curso
+func (p *Commands) activeTargets() []string {
+	targets := make([]string, 0)
+	for _, id := range p.enabledGroups() {
+		if p.Comps.CompExist(id) {
+			targets = append(targets, id)
+		}
+	}
+
+	return targets
+}
+
+// broadcastContent guarda el mensaje del anuncio, muestra como se vera y pregunta a que grupos enviarlo
+func (p *Commands) broadcastContent(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	userID := int64(message.From.ID)
+
+	draft := &broadcast{Text: strings.TrimSpace(message.Text)}
+
+	if fileID, mediaType := messageMedia(message); fileID != "" {
+		draft.FileID = fileID
+		draft.MediaType = mediaType
+		draft.Text = strings.TrimSpace(message.Caption)
+
+		if len([]rune(draft.Text)) > captionLimit {
+			p.reply(chatID, errBroadcastCaption, captionLimit)
+			return
+		}
+	}
+
+	if draft.Text == "" && draft.FileID == "" {
+		p.reply(chatID, errBroadcastEmpty, nil)
+		return
+	}
+
+	err := p.Admins.DeactivateCommand(userID, send_announcement)
+	if err == nil {
+		err = p.Admins.ActiveCommand(userID, broadcastTarget)
+	}
+	if err != nil {
+		log.Printf("error mientras se activaba el comando %s: %v", broadcastTarget, err)
+		return
+	}
+
+	p.broadcasts.set(userID, draft)
+
+	p.reply(chatID, broadcastPreview, nil)
+	p.Sender.Send(chatID, broadcastMessage(chatID, draft))
+
+	all := tgbotapi.NewInlineKeyboardButtonData(p.render(chatID, broadcastButtonAll, len(p.enabledGroups())), targetAll)
+	active := tgbotapi.NewInlineKeyboardButtonData(p.render(chatID, broadcastButtonActive, len(p.activeTargets())), targetActive)
+	cancelButton := tgbotapi.NewInlineKeyboardButtonData(cancel, cancelMarkup)
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(all, active),
+		tgbotapi.NewInlineKeyboardRow(cancelButton),
+	)
+	p.sendReplyWithMarkup(chatID, p.render(chatID, broadcastTargets, nil), &markup)
+}
+
+// broadcastIDs toma los ids de chat enviados por el administrador, solo se envia a los grupos registrados
+func (p *Commands) broadcastIDs(chatID int64, userID int64, text string) {
+	targets := make([]string, 0)
+	ignored := 0
+
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		if _, err := strconv.ParseInt(field, 10, 64); err != nil || !p.Groups.GroupExist(field) {
+			ignored++
+			continue
+		}
+
+		targets = append(targets, field)
+	}
+
+	if len(targets) == 0 {
+		p.reply(chatID, errBroadcastTargets, nil)
+		return
+	}
+
+	p.confirmBroadcast(chatID, userID, targets, ignored)
+}
+
+// confirmBroadcast guarda los grupos elegidos y pide confirmar el envio
+func (p *Commands) confirmBroadcast(chatID int64, userID int64, targets []string, ignored int) {
+	draft, exist := p.broadcasts.get(userID)
+	if !exist {
+		p.reply(chatID, errBroadcastExpired, nil)
+		return
+	}
+
+	err := p.Admins.DeactivateCommand(userID, broadcastTarget)
+	if err == nil {
+		err = p.Admins.ActiveCommand(userID, broadcastConfirm)
+	}
+	if err != nil {
+		log.Printf("error mientras se activaba el comando %s: %v", broadcastConfirm, err)
+		return
+	}
+
+	draft.Targets = targets
+	draft.Ignored = ignored
+
+	send := tgbotapi.NewInlineKeyboardButtonData(p.render(chatID, broadcastButtonSend, nil), broadcastSend)
+	cancelButton := tgbotapi.NewInlineKeyboardButtonData(cancel, cancelMarkup)
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(send, cancelButton))
+	p.sendReplyWithMarkup(chatID, p.render(chatID, broadcastReady, draft), &markup)
+}
+
+// handleBroadcast atiende los botones del anuncio, solo del administrador que lo esta preparando
+func (p *Commands) handleBroadcast(callbackQuery *tgbotapi.CallbackQuery) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Chat.ID
+	userID := int64(callbackQuery.From.ID)
+
+	switch callbackQuery.Data {
+	case targetAll, targetActive:
+		if !p.Admins.CommandStatus(userID, broadcastTarget) {
+			p.reply(chatID, errBroadcastExpired, nil)
+			return
+		}
+
+		targets := p.enabledGroups()
+		if callbackQuery.Data == targetActive {
+			targets = p.activeTargets()
+		}
+
+		if len(targets) == 0 {
+			p.reply(chatID, errBroadcastTargets, nil)
+			return
+		}
+
+		p.confirmBroadcast(chatID, userID, targets, 0)
+	case broadcastSend:
+		if !p.Admins.CommandStatus(userID, broadcastConfirm) {
+			p.reply(chatID, errBroadcastExpired, nil)
+			return
+		}
+
+		draft, exist := p.broadcasts.take(userID)
+		if !exist {
+			p.reply(chatID, errBroadcastExpired, nil)
+			return
+		}
+
+		err := p.Admins.DeactivateCommand(userID, broadcastConfirm)
+		if err != nil {
+			log.Print("error mientras se cerraba session y desactivaba el comando")
+		}
+
+		// Solo se registra el comienzo del texto, /audit muestra varias entradas en un mismo mensaje
+		p.audit(&tgbotapi.Message{From: callbackQuery.From}, core.GlobalScope, "announcement", strconv.Itoa(len(draft.Targets))+" "+campaignName(draft.Text))
+
+		go p.deliverBroadcast(chatID, draft)
+	}
+}
+
+// deliverBroadcast envia el anuncio grupo por grupo, actualiza el progreso y al final envia el reporte con los fallos
+func (p *Commands) deliverBroadcast(chatID int64, draft *broadcast) {
+	result := &broadcastResult{Total: len(draft.Targets)}
+
+	log.Printf("enviando anuncio a %d grupos", result.Total)
+
+	progress := tgbotapi.NewMessage(chatID, p.render(chatID, broadcastProgress, result))
+	progress.ParseMode = messages.ParseMode
+	progressMsg, err := p.Sender.SendWait(chatID, progress)
+	if err != nil {
+		log.Printf("no se pudo enviar el progreso del anuncio: %v", err)
+	}
+
+	ticker := time.NewTicker(broadcastInterval)
+	defer ticker.Stop()
+
+	for _, id := range draft.Targets {
+		<-ticker.C
+
+		groupID, _ := strconv.ParseInt(id, 10, 64)
+
+		_, err := p.Sender.SendWait(groupID, broadcastMessage(groupID, draft))
+		result.Sent++
+
+		if err != nil {
+			reason, detail := failureReason(err)
+			result.Failures = append(result.Failures, &broadcastFailure{GroupID: id, Reason: reason, Detail: detail})
+		} else {
+			result.Delivered++
+		}
+
+		if progressMsg.MessageID != 0 && result.Sent%progressEvery == 0 && result.Sent < result.Total {
+			edit := tgbotapi.NewEditMessageText(chatID, progressMsg.MessageID, p.render(chatID, broadcastProgress, result))
+			edit.ParseMode = messages.ParseMode
+			p.Sender.Send(chatID, edit)
+		}
+	}
+
+	log.Printf("anuncio enviado a %d de %d grupos", result.Delivered, result.Total)
+
+	if len(result.Failures) > reportFailures {
+		result.More = len(result.Failures) - reportFailures
+		result.Failures = result.Failures[:reportFailures]
+	}
+
+	p.reply(chatID, broadcastReport, result)
+}
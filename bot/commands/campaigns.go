@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+const (
+	addcampaign    = "addcampaign"
+	removecampaign = "removecampaign"
+	listCampaigns  = "campaigns"
+
+	usageAddCampaign     = "usage_add_campaign"
+	usageRemoveCampaign  = "usage_remove_campaign"
+	errInvalidCampaign   = "err_invalid_campaign"
+	errCampaignNotFound  = "err_campaign_not_found"
+	campaignAdded        = "campaign_added"
+	campaignRemoved      = "campaign_removed"
+	campaignsList        = "campaigns_list"
+	campaignStatusLive   = "live"
+	campaignStatusQueued = "scheduled"
+	campaignStatusEnded  = "ended"
+)
+
+var (
+	errCampaignField    = errors.New("error: campo de campaña desconocido")
+	errCampaignLink     = errors.New("error: el link de la campaña debe empezar con http://, https:// o tg://")
+	errCampaignGroups   = errors.New("error: los grupos de la campaña deben ser ids de chat separados por coma")
+	errCampaignMissing  = errors.New("error: la campaña necesita duration, text, button y link")
+	errCampaignWeight   = errors.New("error: el peso de la campaña debe ser un numero mayor a 0")
+	errCampaignMediaArg = errors.New("error: media solo admite none, para otra media responde a un mensaje con ella")
+)
+
+// campaignLine es una campaña en la lista de /campaigns con su estado actual
+type campaignLine struct {
+	promotions.Campaign
+	Status string
+}
+
+// campaignFields son los campos de /addcampaign y advertFields los que puede elegir un anunciante con /advertise
+var (
+	campaignFields = map[string]bool{"start": true, "duration": true, "weight": true, "groups": true, "text": true, "button": true, "link": true, "media": true}
+	advertFields   = map[string]bool{"text": true, "button": true, "link": true, "media": true}
+)
+
+// parseCampaign lee una campaña escrita como lineas "clave: valor". Las lineas sin clave continuan el texto del anuncio.
+func parseCampaign(arguments string, now time.Time) (promotions.Campaign, error) {
+	campaign, duration, err := parseAdFields(arguments, now, campaignFields)
+	if err != nil {
+		return campaign, err
+	}
+
+	if duration == 0 {
+		return campaign, errCampaignMissing
+	}
+
+	campaign.End = campaign.Start + int64(duration.Seconds())
+
+	return campaign, nil
+}
+
+// parseAdFields lee los campos permitidos de un anuncio, text, button y link son obligatorios
+func parseAdFields(arguments string, now time.Time, fields map[string]bool) (promotions.Campaign, time.Duration, error) {
+	campaign := promotions.Campaign{Weight: 1, Start: now.Unix()}
+
+	var duration time.Duration
+	last := ""
+
+	for _, line := range strings.Split(arguments, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if !found || !fields[key] {
+			key = ""
+		}
+
+		switch {
+		case key == "start":
+			start, err := campaignStart(value, now)
+			if err != nil {
+				return campaign, 0, err
+			}
+			campaign.Start = start
+		case key == "duration":
+			parsed, err := core.ParseDuration(value)
+			if err != nil {
+				return campaign, 0, err
+			}
+			duration = parsed
+		case key == "weight":
+			weight, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || weight <= 0 {
+				return campaign, 0, errCampaignWeight
+			}
+			campaign.Weight = weight
+		case key == "groups":
+			groups, err := campaignGroups(value)
+			if err != nil {
+				return campaign, 0, err
+			}
+			campaign.Groups = groups
+		case key == "text":
+			campaign.AdName = value
+		case key == "button":
+			campaign.ButtonName = value
+		case key == "link":
+			if !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "tg://") {
+				return campaign, 0, errCampaignLink
+			}
+			campaign.ButtonLink = value
+		case key == "media":
+			if !strings.EqualFold(value, promotions.MediaNone) {
+				return campaign, 0, errCampaignMediaArg
+			}
+			campaign.MediaType = promotions.MediaNone
+		case last == "text":
+			campaign.AdName += "\n" + strings.TrimRight(line, " ")
+			continue
+		case strings.TrimSpace(line) == "":
+			continue
+		default:
+			return campaign, 0, errCampaignField
+		}
+
+		last = key
+	}
+
+	campaign.AdName = strings.TrimSpace(campaign.AdName)
+
+	if campaign.AdName == "" || campaign.ButtonName == "" || campaign.ButtonLink == "" {
+		return campaign, 0, errCampaignMissing
+	}
+
+	return campaign, duration, nil
+}
+
+// campaignStart acepta now, una fecha como "2026-11-01 18:00 UTC" o una demora como 2h
+func campaignStart(value string, now time.Time) (int64, error) {
+	if strings.EqualFold(value, "now") || value == "" {
+		return now.Unix(), nil
+	}
+
+	date, err := core.ParseDate(value, now)
+	if err == nil {
+		return date.Unix(), nil
+	}
+
+	delay, errDelay := core.ParseDuration(value)
+	if errDelay != nil {
+		return 0, err
+	}
+
+	return now.Add(delay).Unix(), nil
+}
+
+func campaignGroups(value string) ([]string, error) {
+	if value == "" || strings.EqualFold(value, "all") {
+		return nil, nil
+	}
+
+	groups := make([]string, 0)
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return nil, errCampaignGroups
+		}
+
+		groups = append(groups, id)
+	}
+
+	return groups, nil
+}
+
+func campaignStatus(campaign promotions.Campaign, now int64) string {
+	switch {
+	case now < campaign.Start:
+		return campaignStatusQueued
+	case now >= campaign.End:
+		return campaignStatusEnded
+	default:
+		return campaignStatusLive
+	}
+}
+
+func (c *Commands) handleCampaigns(update tgbotapi.Update) {
+	message := update.Message
+	chatID := message.Chat.ID
+	userID := int64(message.From.ID)
+
+	if !message.Chat.IsPrivate() {
+		c.reply(chatID, notGroups, nil)
+		return
+	}
+
+	if !c.Admins.AdminExist(userID) {
+		log.Printf("el usuario %s no es un administrador del bot", message.From.UserName)
+		c.reply(chatID, errAdminNotAllowed, nil)
+		return
+	}
+
+	now := time.Now()
+
+	switch message.Command() {
+	case addcampaign:
+		arguments := strings.TrimSpace(message.CommandArguments())
+		if arguments == "" {
+			c.reply(chatID, usageAddCampaign, nil)
+			return
+		}
+
+		campaign, err := parseCampaign(arguments, now)
+		if err != nil {
+			log.Printf("campaña invalida enviada por el usuario %s: %v", message.From.UserName, err)
+			c.reply(chatID, errInvalidCampaign, nil)
+			return
+		}
+
+		// La media de la campaña es la del mensaje al que se responde con el comando
+		if fileID, mediaType := messageMedia(message.ReplyToMessage); fileID != "" {
+			campaign.MediaFileID = fileID
+			campaign.MediaType = mediaType
+		}
+
+		id, err := c.promotions.AddCampaign(campaign)
+		if err != nil {
+			log.Printf("no se pudo agregar la campaña: %v", err)
+			c.reply(chatID, errInvalidCampaign, nil)
+			return
+		}
+
+		campaign.ID = id
+
+		c.audit(message, core.GlobalScope, addcampaign, strconv.FormatInt(id, 10))
+		c.reply(chatID, campaignAdded, &campaignLine{Campaign: campaign, Status: campaignStatus(campaign, now.Unix())})
+	case removecampaign:
+		id, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+		if err != nil {
+			c.reply(chatID, usageRemoveCampaign, nil)
+			return
+		}
+
+		err = c.promotions.RemoveCampaign(id)
+		if err != nil {
+			c.reply(chatID, errCampaignNotFound, nil)
+			return
+		}
+
+		c.audit(message, core.GlobalScope, removecampaign, strconv.FormatInt(id, 10))
+		c.reply(chatID, campaignRemoved, id)
+	case listCampaigns:
+		campaigns := c.promotions.Campaigns()
+
+		lines := make([]*campaignLine, 0, len(campaigns))
+		for _, campaign := range campaigns {
+			lines = append(lines, &campaignLine{Campaign: campaign, Status: campaignStatus(campaign, now.Unix())})
+		}
+
+		c.reply(chatID, campaignsList, lines)
+	}
+}
@@ -1,17 +1,22 @@
 package commands
 
 import (
-	"fmt"
+	"database/sql"
+	"errors"
 	"log"
-	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
 	"github.com/polarysfoundation/kilocompbot/bot/notificator"
 	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/bot/sender"
 	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/database"
 	"github.com/polarysfoundation/kilocompbot/getters"
 	"github.com/polarysfoundation/kilocompbot/groups"
 )
@@ -25,68 +30,194 @@ const (
 	removebuyer  = "removebuyer"
 	addemoji     = "addemoji"
 	list         = "list"
+	rules        = "rules"
+	setminbuy    = "setminbuy"
+	setmaxbuy    = "setmaxbuy"
+	sellpolicy   = "sellpolicy"
+
+	exclude          = "exclude"
+	unexclude        = "unexclude"
+	excluded         = "excluded"
+	announceexcluded = "announceexcluded"
+
+	ban    = "ban"
+	unban  = "unban"
+	banned = "banned"
+
+	schedulecomp = "schedulecomp"
+
+	extend  = "extend"
+	shorten = "shorten"
+	pause   = "pause"
+	resume  = "resume"
+
+	reminders = "reminders"
+
+	liveboard = "liveboard"
+	buyalerts = "buyalerts"
+
+	burst = "burst"
+
+	setmedia    = "setmedia"
+	settemplate = "settemplate"
+
+	language = "language"
 )
 
 const (
-	invalidJetton           = "Invalid jetton, please check and try again"
-	onlyAdmin               = "This command only can be used by admins"
-	onlyGroups              = "Please first add me to a group and make me an administrator"
-	groupAdded              = "Group successfully added"
-	groupAlreadyExist       = "This group has already been added and started successfully, try another command. "
-	addToken                = "Please send the jetton token address"
-	initGroup               = "First initialise the bot with the start kilo command"
-	tokenRemoved            = "Token removed successfully"
-	errorUnexpected         = "Unexpected error, please try again."
-	errorJettonAlreadyAdded = "You already have a jetton address, please delete it and add the new one."
-	tokenAdded              = "New jetton address successfully added."
-	errNothingToDelete      = "Nothing to delete"
-	errCompAlreadyActive    = "This group already has an active competition, please wait for it to stop or stop manually with /stopcomp."
-	errWithoutJetton        = "I'm sorry this group doesn't have a valid jetton address. "
-	addTimestamp            = "How many hours should the contest last? Reply with 24, 48 or 72"
-	errInvalidFormatHours   = "Invalid hours format for the competition, check and try again."
-	competitionStarted      = "The competition has started, let the buys begin!\n\nOnly direct buys with TON will be included. If you sell you will be removed from the contest and your future buys won't count."
-	errCompNotActive        = "Sorry, the group has no active competition. "
-	addNewEmoji             = "Cool, send the new emoji. "
-	emojiAdded              = "The emoji has been changed."
-	competitionEnded        = "The competition is over."
-	emptyList               = "The list of competitors is empty. "
-
-	purchaseRemoved = "The purchase has been removed"
-
-	actionCanceled = "action canceled"
+	invalidJetton           = "invalid_jetton"
+	onlyGroups              = "only_groups"
+	groupAdded              = "group_added"
+	groupAlreadyExist       = "group_already_exist"
+	addToken                = "add_token"
+	initGroup               = "init_group"
+	tokenRemoved            = "token_removed"
+	errorUnexpected         = "error_unexpected"
+	errorJettonAlreadyAdded = "error_jetton_already_added"
+	tokenAdded              = "token_added"
+	errNothingToDelete      = "err_nothing_to_delete"
+	errCompAlreadyActive    = "err_comp_already_active"
+	errWithoutJetton        = "err_without_jetton"
+	addTimestamp            = "add_timestamp"
+	errInvalidFormatHours   = "err_invalid_format_hours"
+	errCompNotActive        = "err_comp_not_active"
+	addNewEmoji             = "add_new_emoji"
+	emojiAdded              = "emoji_added"
+	competitionEnded        = "comp_ended"
+	emptyList               = "empty_list"
+
+	purchaseRemoved = "purchase_removed"
+
+	usageSetMinBuy    = "usage_set_min_buy"
+	usageSetMaxBuy    = "usage_set_max_buy"
+	errInvalidAmount  = "err_invalid_amount"
+	errMinAboveMax    = "err_min_above_max"
+	minBuyUpdated     = "min_buy_updated"
+	maxBuyUpdated     = "max_buy_updated"
+	usageSellPolicy   = "usage_sell_policy"
+	errInvalidPercent = "err_invalid_percent"
+	sellPolicyUpdated = "sell_policy_updated"
+
+	usageExclude          = "usage_exclude"
+	usageUnexclude        = "usage_unexclude"
+	usageAnnounceExcluded = "usage_announce_excluded"
+	invalidWallet         = "invalid_wallet"
+	walletExcluded        = "wallet_excluded"
+	walletIncluded        = "wallet_included"
+	errWalletExcluded     = "err_wallet_excluded"
+	errWalletNotExcluded  = "err_wallet_not_excluded"
+	emptyExcluded         = "empty_excluded"
+	announceExcludedOn    = "announce_excluded_on"
+	announceExcludedOff   = "announce_excluded_off"
+
+	usageBan           = "usage_ban"
+	usageUnban         = "usage_unban"
+	defaultBanReason   = "banned by an admin"
+	walletBanned       = "wallet_banned"
+	walletUnbanned     = "wallet_unbanned"
+	errWalletBanned    = "err_wallet_banned"
+	errWalletNotBanned = "err_wallet_not_banned"
+	emptyBanned        = "empty_banned"
+
+	usageScheduleComp   = "usage_schedule_comp"
+	errInvalidStart     = "err_invalid_start"
+	compScheduled       = "comp_scheduled"
+	scheduleCanceled    = "schedule_canceled"
+	errNothingScheduled = "err_nothing_scheduled"
+
+	usageExtend        = "usage_extend"
+	usageShorten       = "usage_shorten"
+	errInvalidDuration = "err_invalid_duration"
+	errAdjustRange     = "err_adjust_range"
+	compExtended       = "comp_extended"
+	compShortened      = "comp_shortened"
+	compPaused         = "comp_paused"
+	compResumed        = "comp_resumed"
+	errCompPaused      = "err_comp_paused"
+	errCompNotPaused   = "err_comp_not_paused"
+
+	usageReminders     = "usage_reminders"
+	errInvalidReminder = "err_invalid_reminder"
+	remindersCurrent   = "reminders_current"
+	remindersOff       = "reminders_off"
+	remindersUpdated   = "reminders_updated"
+
+	usageLiveBoard = "usage_live_board"
+	usageBuyAlerts = "usage_buy_alerts"
+	liveBoardOn    = "live_board_on"
+	liveBoardOff   = "live_board_off"
+	buyAlertsSet   = "buy_alerts_set"
+
+	usageBurst   = "usage_burst"
+	burstUpdated = "burst_updated"
+	burstOff     = "burst_off"
+
+	usageSetMedia      = "usage_set_media"
+	groupMediaUpdated  = "group_media_updated"
+	groupMediaReset    = "group_media_reset"
+	usageSetTemplate   = "usage_set_template"
+	errTemplateTooLong = "err_template_too_long"
+	groupTemplateSet   = "group_template_set"
+	groupTemplateReset = "group_template_reset"
+	currentTemplate    = "current_template"
+	maxTemplateLength  = 700
+
+	usageLanguage      = "usage_language"
+	errInvalidLanguage = "err_invalid_language"
+	languageUpdated    = "language_updated"
+	languagePrivate    = "language_private"
+
+	actionCanceled = "action_canceled"
 )
 
 type Commands struct {
-	Groups *groups.Groups
-	Temps  *groups.ActiveTemps
-	Comps  *core.Competition
-	Admins *Admins
+	Groups     *groups.Groups
+	Temps      *groups.ActiveTemps
+	Comps      *core.Competition
+	Admins     *Admins
+	Exclusions *core.Exclusions
+	Roles      *core.Roles
+	DB         *sql.DB
 
 	events *notificator.Groups
 
 	promotions *promotions.Params
+	bookings   *bookings.Bookings
+	tracker    *promotions.Tracker
+	broadcasts *broadcasts
+
+	BotAPI   *tgbotapi.BotAPI
+	Sender   *sender.Queue
+	Messages *messages.Templates
 
-	BotAPI *tgbotapi.BotAPI
+	// locales guarda el language_code de cada usuario para responderle en su idioma en el chat privado
+	locales      map[int64]string
+	localesMutex sync.RWMutex
 }
 
-func InitCommands(groups *groups.Groups, temps *groups.ActiveTemps, comps *core.Competition, admins *Admins, bot *tgbotapi.BotAPI, promo *promotions.Params, events *notificator.Groups) *Commands {
+func InitCommands(groups *groups.Groups, temps *groups.ActiveTemps, comps *core.Competition, admins *Admins, bot *tgbotapi.BotAPI, queue *sender.Queue, texts *messages.Templates, promo *promotions.Params, events *notificator.Groups, exclusions *core.Exclusions, roles *core.Roles, ads *bookings.Bookings, tracker *promotions.Tracker, db *sql.DB) *Commands {
 	return &Commands{
 		Groups:     groups,
 		Temps:      temps,
 		Comps:      comps,
 		Admins:     admins,
+		Exclusions: exclusions,
+		Roles:      roles,
+		DB:         db,
 		promotions: promo,
+		bookings:   ads,
+		tracker:    tracker,
+		broadcasts: initBroadcasts(),
 		events:     events,
 		BotAPI:     bot,
+		Sender:     queue,
+		Messages:   texts,
+		locales:    make(map[int64]string),
 	}
 }
 
 func (c *Commands) HandleGroup(updates <-chan tgbotapi.Update) {
 
-	c.Admins.AddAdmin("uranusnfts")
-	c.Admins.AddAdmin("Mrforrestgump84")
-	c.Admins.AddAdmin("Tobster_LCL")
-
 	/* 	purchases := core.InitPurchases()
 	   	sales := core.InitSales()
 	*/
@@ -97,32 +228,36 @@ func (c *Commands) HandleGroup(updates <-chan tgbotapi.Update) {
 			buttonContext := callbackQuery.Data
 
 			if buttonContext == cancelMarkup {
-				err := c.Admins.DeactivateCommand(change_text)
+				// Solo se cancela la accion pendiente de quien presiono el boton
+				err := c.Admins.CancelCommand(int64(callbackQuery.From.ID))
 				if err != nil {
 					log.Print("error mientras se cerraba session y desactivaba el comando")
 				}
 
-				err = c.Admins.DeactivateCommand(change_video)
-				if err != nil {
-					log.Print("error mientras se cerraba session y desactivaba el comando")
-				}
+				c.reply(update.CallbackQuery.Message.Chat.ID, actionCanceled, nil)
+			}
 
-				err = c.Admins.DeactivateCommand(change_button_content)
-				if err != nil {
-					log.Print("error mientras se cerraba session y desactivaba el comando")
-				}
+			if strings.HasPrefix(buttonContext, bookPrefix) {
+				c.handleBooking(callbackQuery)
+			}
 
-				err = c.Admins.DeactivateCommand(change_button_context)
-				if err != nil {
-					log.Print("error mientras se cerraba session y desactivaba el comando")
-				}
+			if strings.HasPrefix(buttonContext, broadcastPrefix) {
+				c.handleBroadcast(callbackQuery)
+			}
 
-				c.send(update.CallbackQuery.Message.Chat.ID, actionCanceled)
+			if strings.HasPrefix(buttonContext, groupsPrefix) {
+				c.handleGroupManager(callbackQuery)
 			}
 			continue
 		}
 
 		if update.Message != nil {
+			c.rememberLocale(update.Message)
+
+			if c.groupDisabled(update.Message) {
+				continue
+			}
+
 			c.handleAdminCommands(update)
 			c.handleCommands(update)
 			c.handleNoCommands(update)
@@ -154,13 +289,13 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
@@ -176,22 +311,24 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 				}
 
 				log.Printf("grupo con ID: %s, agregado correctamente", chatIDStr)
-				c.send(chatID, groupAdded)
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, start, "")
+				c.reply(chatID, groupAdded, nil)
 				return
 			} else {
 				log.Printf("grupo con ID: %s, no puede agregarse por que ya existe", chatIDStr)
-				c.send(chatID, groupAlreadyExist)
+				c.reply(chatID, groupAlreadyExist, nil)
 			}
 		case addtoken:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
@@ -210,26 +347,26 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 
 				if group.JettonAddress != "" {
 					log.Printf("el grupo %s, ya tiene una direccion activa", chatIDStr)
-					c.send(chatID, errorJettonAlreadyAdded)
+					c.reply(chatID, errorJettonAlreadyAdded, nil)
 					return
 				}
 
-				c.send(chatID, addToken)
+				c.reply(chatID, addToken, nil)
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
 		case removetoken:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
@@ -242,28 +379,30 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 
 				if group.JettonAddress == "" {
 					log.Printf("no existe una direccion que remover para el grupo %s", chatIDStr)
-					c.send(chatID, errNothingToDelete)
+					c.reply(chatID, errNothingToDelete, nil)
 					return
 				}
 
 				group.JettonAddress = ""
 
-				c.send(chatID, tokenRemoved)
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, removetoken, "")
+				c.reply(chatID, tokenRemoved, nil)
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
 		case startnewcomp:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
@@ -276,43 +415,38 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 
 				if group.CompActive {
 					log.Printf("ya existe una competicion activa para el grupo %s", chatIDStr)
-					c.send(chatID, errCompAlreadyActive)
+					c.reply(chatID, errCompAlreadyActive, nil)
 					return
 				}
 
 				if group.JettonAddress == "" {
 					log.Printf("el grupo %s, no tiene una direccion activa", chatIDStr)
-					c.send(chatID, errWithoutJetton)
+					c.reply(chatID, errWithoutJetton, nil)
 					return
 				}
 
-				err = c.Comps.RemoveCompActive(chatIDStr)
-				if err != nil {
-					log.Printf("no se pudo remover la comp para el grupo %s", chatIDStr)
-				}
-
 				err = c.Temps.ChangeTemp(2, chatIDStr, true)
 				if err != nil {
 					log.Printf("no se pudo actualizar el temp, %v", err)
 					return
 				}
 
-				c.send(chatID, addTimestamp)
+				c.reply(chatID, addTimestamp, nil)
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
 		case stopcomp:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
@@ -325,52 +459,33 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 
 				if !group.CompActive {
 					log.Printf("no existe una competicion activa para el grupo %s", chatIDStr)
-					c.send(chatID, errCompNotActive)
+					c.reply(chatID, errCompNotActive, nil)
 					return
 				}
 
 				if group.JettonAddress == "" {
 					log.Printf("el grupo %s, no tiene una direccion activa", chatIDStr)
-					c.send(chatID, errWithoutJetton)
+					c.reply(chatID, errWithoutJetton, nil)
 					return
 				}
 
-				err = c.events.RemoveTicker(chatIDStr)
-				if err != nil {
-					log.Printf("no se pudo remover el ticker para el grupo %s, %v", chatIDStr, err)
-				}
-
-				err = c.Comps.RemoveBlacklistActive(chatIDStr)
-				if err != nil {
-					log.Printf("no se pudo remover el blacklist para el grupo %s", chatIDStr)
-				}
-
-				err = c.Comps.RemoveTimestampActive(chatIDStr)
-				if err != nil {
-					log.Printf("no se pudo remover el timestamp para el grupo %s", chatIDStr)
-				}
-
-				err = c.Groups.UpdateCompStatus(chatIDStr, false)
-				if err != nil {
-					log.Printf("no se pudo remover el status para el grupo %s", chatIDStr)
-				}
-
-				c.send(chatID, competitionEnded)
+				c.stopCompetition(chatIDStr)
+				c.audit(update.Message, chatIDStr, stopcomp, "")
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
 		case addemoji:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
@@ -381,16 +496,16 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 					return
 				}
 
-				c.send(chatID, addNewEmoji)
+				c.reply(chatID, addNewEmoji, nil)
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
 		case list:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
@@ -404,313 +519,1356 @@ func (c *Commands) handleCommands(update tgbotapi.Update) {
 				compList := order.GetCompList()
 
 				if len(compList) == 0 {
-					c.send(chatID, emptyList)
+					c.reply(chatID, emptyList, nil)
 					return
 				}
 
-				msg := listMessage(compList)
-				c.send(chatID, msg)
+				c.reply(chatID, "top_buyers", topBuyers(compList))
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
-		case admin:
-			return
-		case "ca":
-			return
-		case removebuyer:
+		case rules:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				c.send(chatID, c.rulesMessage(chatID, group))
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case setminbuy, setmaxbuy:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
 			if exist {
+				usage := usageSetMinBuy
+				if update.Message.Command() == setmaxbuy {
+					usage = usageSetMaxBuy
+				}
+
 				parts := strings.SplitN(param, " ", 2)
 				if len(parts) < 2 {
-					c.send(chatID, "Please provide the buyer's address. Usage: /removebuyer <address>")
+					c.reply(chatID, usage, nil)
 					return
 				}
 
-				buyerAddress := parts[1]
+				amount, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+				if err != nil || amount < 0 {
+					log.Printf("monto invalido para el grupo %s: %s", chatIDStr, parts[1])
+					c.reply(chatID, errInvalidAmount, nil)
+					return
+				}
 
-				purchase, err := c.Comps.GetComp(chatIDStr)
+				group, err := c.Groups.GetDataGroup(chatIDStr)
 				if err != nil {
-					log.Printf("no se pudo obtener la competencia para el grupo %s", chatIDStr)
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
 					return
 				}
-				_, hash, err := purchase.GetPurchaseAndHashByBuyer(buyerAddress)
-				if err != nil {
-					log.Printf("no se pudo obtener la compra para el grupo %s", chatIDStr)
+
+				if update.Message.Command() == setminbuy {
+					if group.MaxBuy > 0 && amount > group.MaxBuy {
+						c.reply(chatID, errMinAboveMax, nil)
+						return
+					}
+
+					group.MinBuy = amount
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, setminbuy, parts[1])
+					c.reply(chatID, minBuyUpdated, nil)
 					return
 				}
 
-				err = purchase.RemovePurchase(hash)
-				if err != nil {
-					log.Printf("no se pudo remover la compra para el grupo %s", chatIDStr)
+				if amount > 0 && amount < group.MinBuy {
+					c.reply(chatID, errMinAboveMax, nil)
 					return
 				}
 
-				c.send(chatID, purchaseRemoved)
+				group.MaxBuy = amount
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, setmaxbuy, parts[1])
+				c.reply(chatID, maxBuyUpdated, nil)
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case sellpolicy:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
-		default:
-			c.defaultHandler(update)
-			return
-		}
-	}
-}
-
-func (c *Commands) handleNoCommands(update tgbotapi.Update) {
-	chatIDStr := strconv.FormatInt(update.Message.Chat.ID, 10)
-	chatID := update.Message.Chat.ID
 
-	exist := c.Groups.GroupExist(chatIDStr)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
 
-	chatConfig := tgbotapi.ChatConfig{ChatID: chatID}
-	chat, err := c.BotAPI.GetChat(chatConfig)
-	if err != nil {
-		log.Println(err)
-	}
+			if exist {
+				parts := strings.Fields(param)
+				if len(parts) < 2 || !core.ValidSellPolicy(parts[1]) {
+					c.reply(chatID, usageSellPolicy, nil)
+					return
+				}
 
-	if update.Message.From.ID == c.BotAPI.Self.ID {
-		return
-	}
+				var tolerance int64
+				if parts[1] == core.SellTolerate {
+					if len(parts) < 3 {
+						c.reply(chatID, usageSellPolicy, nil)
+						return
+					}
 
-	if !update.Message.IsCommand() && !chat.IsPrivate() {
-		userID := update.Message.From.ID
+					tolerance, err = strconv.ParseInt(strings.TrimSuffix(parts[2], "%"), 10, 64)
+					if err != nil || tolerance < 1 || tolerance > 100 {
+						log.Printf("porcentaje invalido para el grupo %s: %s", chatIDStr, parts[2])
+						c.reply(chatID, errInvalidPercent, nil)
+						return
+					}
+				}
 
-		temps, err := c.Temps.GetActiveTemps(chatIDStr)
-		if err != nil {
-			log.Printf("error cargando los temps del grupo %s: %s", chatIDStr, err)
-			return
-		}
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
 
-		if temps.AwaitingToken {
-			param := update.Message.Text
+				group.SellPolicy = parts[1]
+				group.SellTolerance = tolerance
 
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, sellpolicy, strings.Join(parts[1:], " "))
+				c.send(chatID, c.render(chatID, sellPolicyUpdated, nil)+"\n\n"+c.render(chatID, "sell_rule", group))
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case exclude, unexclude:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
 			if exist {
-				isAddress, err := getters.IsAddress(param)
-				if err != nil {
-					log.Printf("no se pudo comprobar si el parametro era una direccion")
-					c.send(chatID, errorUnexpected)
+				usage := usageExclude
+				if update.Message.Command() == unexclude {
+					usage = usageUnexclude
+				}
+
+				parts := strings.Fields(param)
+				if len(parts) < 2 {
+					c.reply(chatID, usage, nil)
 					return
 				}
 
-				if isAddress {
+				wallet, err := getters.NormalizeAddress(parts[1])
+				if err != nil {
+					log.Printf("direccion invalida para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, invalidWallet, nil)
+					return
+				}
 
-					group, err := c.Groups.GetDataGroup(chatIDStr)
+				if update.Message.Command() == unexclude {
+					err = c.Exclusions.Remove(chatIDStr, wallet)
 					if err != nil {
-						log.Printf("no se pudo obtener los datos del grupo")
+						log.Printf("no se pudo remover la exclusion para el grupo %s: %v", chatIDStr, err)
+						c.reply(chatID, errWalletNotExcluded, nil)
 						return
 					}
 
-					if group.JettonAddress != "" {
-						log.Printf("el grupo %s, ya tiene una direccion activa", chatIDStr)
-						c.send(chatID, errorJettonAlreadyAdded)
-						return
-					}
+					c.audit(update.Message, chatIDStr, unexclude, wallet)
+					c.reply(chatID, walletIncluded, nil)
+					return
+				}
 
-					group.JettonAddress = param
+				err = c.Exclusions.Add(chatIDStr, wallet)
+				if err != nil {
+					log.Printf("no se pudo excluir la wallet para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, errWalletExcluded, nil)
+					return
+				}
 
-					err = c.Groups.AddPools(chatIDStr)
-					if err != nil {
-						log.Printf("no se pudo obtener los pools, %v", err)
-						return
+				if c.Comps.CompExist(chatIDStr) {
+					purchases, err := c.Comps.GetComp(chatIDStr)
+					if err == nil {
+						purchases.RemovePurchasesByBuyer(wallet)
 					}
+				}
 
-					err = c.Temps.ChangeTemp(0, chatIDStr, false)
-					if err != nil {
-						log.Printf("no se pudo actualizar el temp, %v", err)
-						return
-					}
+				c.audit(update.Message, chatIDStr, exclude, wallet)
+				c.reply(chatID, walletExcluded, nil)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case excluded:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
 
-					c.send(chatID, tokenAdded)
-					return
-				} else {
-					log.Printf("direccion de jetton invalida para el grupo %s", chatIDStr)
-					c.send(chatID, invalidJetton)
+			if exist {
+				wallets := c.Exclusions.List(chatIDStr)
+				if len(wallets) == 0 {
+					c.reply(chatID, emptyExcluded, nil)
 					return
 				}
+
+				c.reply(chatID, "excluded_wallets", wallets)
+				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
-
-		}
-
-		if temps.AwaitingEmoji {
-			param := update.Message.Text
-
+		case announceexcluded:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
 			if exist {
-				group, err := c.Groups.GetDataGroup(chatIDStr)
-				if err != nil {
-					log.Printf("no se pudo obtener los datos del grupo")
+				parts := strings.Fields(param)
+				if len(parts) < 2 || (parts[1] != "on" && parts[1] != "off") {
+					c.reply(chatID, usageAnnounceExcluded, nil)
 					return
 				}
 
-				group.Emoji = param
-
-				err = c.Temps.ChangeTemp(1, chatIDStr, false)
+				group, err := c.Groups.GetDataGroup(chatIDStr)
 				if err != nil {
-					log.Printf("no se pudo actualizar el temp, %v", err)
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
 					return
 				}
 
-				c.send(chatID, emojiAdded)
+				group.AnnounceExcluded = parts[1] == "on"
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, announceexcluded, parts[1])
+
+				if group.AnnounceExcluded {
+					c.reply(chatID, announceExcludedOn, nil)
+				} else {
+					c.reply(chatID, announceExcludedOff, nil)
+				}
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
 				return
 			}
-
-		}
-
-		if temps.AwaitingTimestamp {
-			param := update.Message.Text
-
+		case ban, unban:
 			if !chat.IsGroup() && !chat.IsSuperGroup() {
 				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
-				c.send(chatID, onlyGroups)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
 
-			if !c.isAdmin(userID, chatID) {
-				log.Printf("el usuario %s, no es un administrador", update.Message.From.UserName)
-				c.send(chatID, onlyAdmin)
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
 				return
 			}
 
 			if exist {
-				var timestamp int64
-				switch param {
-				case "24", "48", "72", "5":
-					switch param {
-					case "24":
-						timestamp = time.Now().Unix() + int64(24*60*60)
-					case "48":
-						timestamp = time.Now().Unix() + int64(24*60*60*2)
-					case "72":
-						timestamp = time.Now().Unix() + int64(24*60*60*3)
-					case "5":
-						timestamp = time.Now().Unix() + int64(5*60)
-					}
-				default:
-					log.Printf("parametro invalido para comenzar la competencia")
-					c.send(chatID, errInvalidFormatHours)
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
 					return
 				}
 
-				err := c.Comps.NewTimestamp(chatIDStr, timestamp)
-				if err != nil {
-					log.Printf("no se pudo crear el nuevo timestamp para el grupo %s", chatIDStr)
+				if !group.CompActive {
+					log.Printf("no existe una competicion activa para el grupo %s", chatIDStr)
+					c.reply(chatID, errCompNotActive, nil)
 					return
 				}
 
-				err = c.events.AddNewTicker(chatIDStr)
+				usage := usageBan
+				if update.Message.Command() == unban {
+					usage = usageUnban
+				}
+
+				parts := strings.SplitN(param, " ", 3)
+				if len(parts) < 2 {
+					c.reply(chatID, usage, nil)
+					return
+				}
+
+				wallet, err := getters.NormalizeAddress(strings.TrimSpace(parts[1]))
 				if err != nil {
-					log.Printf("no se pudo agregar el ticker para el grupo %s", chatIDStr)
+					log.Printf("direccion invalida para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, invalidWallet, nil)
+					return
+				}
+
+				if update.Message.Command() == unban {
+					_, err = c.Comps.Unban(chatIDStr, wallet)
+					if err != nil {
+						log.Printf("no se pudo reincorporar la wallet %s en el grupo %s: %v", wallet, chatIDStr, err)
+						c.reply(chatID, errWalletNotBanned, nil)
+						return
+					}
+
+					c.events.MarkBoard(chatIDStr)
+					c.audit(update.Message, chatIDStr, unban, wallet)
+					c.reply(chatID, walletUnbanned, nil)
 					return
 				}
 
-				c.Groups.ActiveGroups[chatIDStr].CompActive = true
+				reason := defaultBanReason
+				if len(parts) == 3 && strings.TrimSpace(parts[2]) != "" {
+					reason = strings.TrimSpace(parts[2])
+				}
 
-				err = c.Temps.ChangeTemp(2, chatIDStr, false)
+				_, err = c.Comps.Ban(chatIDStr, wallet, reason, userTag(update.Message.From))
 				if err != nil {
-					log.Printf("no se pudo actualizar el temp, %v", err)
+					log.Printf("no se pudo descalificar la wallet %s en el grupo %s: %v", wallet, chatIDStr, err)
+					c.reply(chatID, errWalletBanned, nil)
 					return
 				}
 
-				c.send(chatID, competitionStarted)
+				c.events.MarkBoard(chatIDStr)
+				c.audit(update.Message, chatIDStr, ban, wallet+" "+reason)
+				c.reply(chatID, walletBanned, nil)
 				return
 			} else {
-				c.send(chatID, initGroup)
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case banned:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
 				return
 			}
-		}
-	}
-}
-
-func (c *Commands) isAdmin(id int, chatID int64) bool {
-	// Verificar si el bot es un administrador del grupo
-	memberConfig := tgbotapi.ChatConfigWithUser{
-		ChatID: chatID,
-		UserID: id,
-	}
-	member, err := c.BotAPI.GetChatMember(memberConfig)
-	if err != nil {
-		log.Println(err)
-	}
-
-	return member.IsAdministrator() || member.IsCreator()
-}
 
-func (b *Commands) defaultHandler(update tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, "I'm sorry, I didn't get your message. please contact with admins for more info")
-	_, err := b.BotAPI.Send(msg)
-	if err != nil {
-		log.Println("Error al enviar mensaje:", err)
-	}
-}
+			if exist {
+				list := c.Comps.Banned(chatIDStr)
+				if len(list) == 0 {
+					c.reply(chatID, emptyBanned, nil)
+					return
+				}
 
-// ListMessage function to generate the message list
-func listMessage(buyers []*core.Purchase) string {
-	header := "👑 *Top Buyers*:\n\n"
+				c.reply(chatID, "banned_wallets", list)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case extend, shorten:
+			command := update.Message.Command()
 
-	for i := 0; i < 10; i++ {
-		if i < len(buyers) && buyers[i] != nil {
-			tx := buyers[i]
-			tonSpent := tx.Ton
-			wallet := tx.Buyer
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
 
-			var walletShortened, walletLink string
-			if wallet != "" {
-				walletShortened = fmt.Sprintf("%s...%s", wallet[:6], wallet[len(wallet)-6:])
-				walletLink = fmt.Sprintf("[%s](https://tonviewer.com/%s)", walletShortened, url.PathEscape(wallet))
-			} else {
-				walletShortened = "not set"
-				walletLink = "not set"
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
 			}
 
-			header += fmt.Sprintf("%d.) %s *TON* - %s\n", i+1, tonSpent, walletLink)
-		} else {
-			header += fmt.Sprintf("%d.) not set *TON* - not set\n", i+1)
-		}
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				if !group.CompActive {
+					log.Printf("no existe una competicion activa para el grupo %s", chatIDStr)
+					c.reply(chatID, errCompNotActive, nil)
+					return
+				}
+
+				usage, announce := usageExtend, compExtended
+				if command == shorten {
+					usage, announce = usageShorten, compShortened
+				}
+
+				parts := strings.Fields(param)
+				if len(parts) < 2 {
+					c.reply(chatID, usage, nil)
+					return
+				}
+
+				delta, err := core.ParseDuration(parts[1])
+				if err != nil {
+					log.Printf("duracion invalida para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, errInvalidDuration, nil)
+					return
+				}
+
+				if command == shorten {
+					delta = -delta
+				}
+
+				_, err = c.Comps.AdjustTimestamp(chatIDStr, delta)
+				if err != nil {
+					log.Printf("no se pudo ajustar el timestamp para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, errAdjustRange, nil)
+					return
+				}
+
+				c.saveEndTime(chatIDStr)
+				c.events.MarkBoard(chatIDStr)
+				c.audit(update.Message, chatIDStr, command, parts[1])
+				c.reply(chatID, announce, &timeChange{Delta: parts[1], TimeLeft: c.Comps.TimeLeft(chatIDStr)})
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case pause, resume:
+			command := update.Message.Command()
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				if !group.CompActive {
+					log.Printf("no existe una competicion activa para el grupo %s", chatIDStr)
+					c.reply(chatID, errCompNotActive, nil)
+					return
+				}
+
+				paused := c.Comps.IsPaused(chatIDStr)
+
+				if command == pause {
+					if paused {
+						c.reply(chatID, errCompPaused, nil)
+						return
+					}
+
+					err = c.Comps.Pause(chatIDStr, time.Now().Unix())
+				} else {
+					if !paused {
+						c.reply(chatID, errCompNotPaused, nil)
+						return
+					}
+
+					_, err = c.Comps.Resume(chatIDStr)
+				}
+
+				if err != nil {
+					log.Printf("no se pudo cambiar la pausa para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, errorUnexpected, nil)
+					return
+				}
+
+				announce := compResumed
+				if command == pause {
+					announce = compPaused
+				}
+
+				c.saveEndTime(chatIDStr)
+				c.events.MarkBoard(chatIDStr)
+				c.audit(update.Message, chatIDStr, command, "")
+				c.reply(chatID, announce, &timeChange{TimeLeft: c.Comps.TimeLeft(chatIDStr)})
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case reminders:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				parts := strings.Fields(param)
+				if len(parts) < 2 {
+					c.send(chatID, c.remindersStatus(chatID, group.Reminders)+"\n\n"+c.render(chatID, usageReminders, nil))
+					return
+				}
+
+				list, err := core.ParseReminders(strings.Join(parts[1:], ""))
+				if err != nil {
+					log.Printf("avisos invalidos para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, errInvalidReminder, nil)
+					return
+				}
+
+				group.Reminders = core.FormatReminders(list)
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, reminders, group.Reminders)
+				c.send(chatID, c.render(chatID, remindersUpdated, nil)+"\n\n"+c.remindersStatus(chatID, group.Reminders))
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case liveboard, buyalerts:
+			command := update.Message.Command()
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				parts := strings.Fields(param)
+
+				if command == buyalerts {
+					if len(parts) != 2 || !notificator.ValidAlerts(parts[1]) {
+						c.reply(chatID, usageBuyAlerts, nil)
+						return
+					}
+
+					group.BuyAlerts = parts[1]
+
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, buyalerts, parts[1])
+					c.reply(chatID, buyAlertsSet, parts[1])
+					return
+				}
+
+				if len(parts) != 2 || (parts[1] != "on" && parts[1] != "off") {
+					c.reply(chatID, usageLiveBoard, nil)
+					return
+				}
+
+				group.LiveBoard = parts[1] == "on"
+
+				// Al desactivarlo se cierra el leaderboard publicado para que no quede fijado sin actualizarse
+				if !group.LiveBoard {
+					c.events.CloseBoard(chatIDStr)
+				}
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, liveboard, parts[1])
+
+				if !group.LiveBoard {
+					c.reply(chatID, liveBoardOff, nil)
+					return
+				}
+
+				c.reply(chatID, liveBoardOn, nil)
+
+				// Si ya hay una competencia en curso se publica el leaderboard de inmediato
+				if group.CompActive && group.BoardMessageID == 0 {
+					c.events.PostBoard(chatIDStr)
+				}
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case burst:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				parts := strings.Fields(param)
+
+				if len(parts) == 2 && parts[1] == "off" {
+					group.BurstThreshold = 0
+
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, burst, "off")
+					c.reply(chatID, burstOff, nil)
+					return
+				}
+
+				if len(parts) != 3 {
+					c.reply(chatID, usageBurst, nil)
+					return
+				}
+
+				threshold, err := strconv.ParseInt(parts[1], 10, 64)
+				if err != nil || threshold <= 0 {
+					c.reply(chatID, usageBurst, nil)
+					return
+				}
+
+				window, err := parseWindow(parts[2])
+				if err != nil {
+					c.reply(chatID, usageBurst, nil)
+					return
+				}
+
+				group.BurstThreshold = threshold
+				group.BurstWindow = int64(window.Seconds())
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, burst, parts[1]+" "+parts[2])
+				c.reply(chatID, burstUpdated, &burstSettings{Threshold: threshold, Window: window})
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case setmedia, settemplate:
+			command := update.Message.Command()
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				arguments := strings.TrimSpace(update.Message.CommandArguments())
+
+				if command == settemplate {
+					switch {
+					case arguments == "":
+						current := ""
+						if group.AlertTemplate != "" {
+							current = c.render(chatID, currentTemplate, group.AlertTemplate) + "\n\n"
+						}
+						c.send(chatID, current+c.render(chatID, usageSetTemplate, notificator.AlertPlaceholders))
+					case arguments == "reset":
+						group.AlertTemplate = ""
+
+						c.saveGroup(chatIDStr)
+						c.audit(update.Message, chatIDStr, settemplate, "reset")
+						c.reply(chatID, groupTemplateReset, nil)
+					case len(arguments) > maxTemplateLength:
+						c.reply(chatID, errTemplateTooLong, maxTemplateLength)
+					default:
+						group.AlertTemplate = arguments
+
+						c.saveGroup(chatIDStr)
+						c.audit(update.Message, chatIDStr, settemplate, arguments)
+						c.reply(chatID, groupTemplateSet, nil)
+					}
+					return
+				}
+
+				switch arguments {
+				case "reset":
+					err = c.Groups.SetAlertMedia(chatIDStr, "", "")
+					if err != nil {
+						log.Printf("no se pudo cambiar la media del grupo %s: %v", chatIDStr, err)
+						return
+					}
+
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, setmedia, "reset")
+					c.reply(chatID, groupMediaReset, nil)
+					return
+				case promotions.MediaNone:
+					err = c.Groups.SetAlertMedia(chatIDStr, "", promotions.MediaNone)
+					if err != nil {
+						log.Printf("no se pudo cambiar la media del grupo %s: %v", chatIDStr, err)
+						return
+					}
+
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, setmedia, promotions.MediaNone)
+					c.reply(chatID, groupMediaUpdated, nil)
+					return
+				}
+
+				fileID, mediaType := messageMedia(update.Message.ReplyToMessage)
+				if fileID == "" {
+					c.reply(chatID, usageSetMedia, nil)
+					return
+				}
+
+				err = c.Groups.SetAlertMedia(chatIDStr, fileID, mediaType)
+				if err != nil {
+					log.Printf("no se pudo cambiar la media del grupo %s: %v", chatIDStr, err)
+					return
+				}
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, setmedia, mediaType)
+				c.reply(chatID, groupMediaUpdated, nil)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case schedulecomp:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				parts := strings.Fields(param)[1:]
+
+				if len(parts) == 1 && parts[0] == "cancel" {
+					if group.ScheduledStart == 0 {
+						c.reply(chatID, errNothingScheduled, nil)
+						return
+					}
+
+					group.ScheduledStart = 0
+					group.ScheduledDuration = 0
+
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, schedulecomp, "cancel")
+					c.reply(chatID, scheduleCanceled, nil)
+					return
+				}
+
+				if len(parts) < 2 {
+					c.reply(chatID, usageScheduleComp, nil)
+					return
+				}
+
+				if group.JettonAddress == "" {
+					log.Printf("el grupo %s, no tiene una direccion activa", chatIDStr)
+					c.reply(chatID, errWithoutJetton, nil)
+					return
+				}
+
+				duration, err := core.ParseDuration(parts[len(parts)-1])
+				if err != nil {
+					log.Printf("duracion invalida para el grupo %s: %v", chatIDStr, err)
+					c.reply(chatID, errInvalidFormatHours, nil)
+					return
+				}
+
+				now := time.Now()
+				startValue := strings.Join(parts[:len(parts)-1], " ")
+
+				start, err := core.ParseDate(startValue, now)
+				if err != nil {
+					delay, errDelay := core.ParseDuration(startValue)
+					if errDelay != nil {
+						log.Printf("inicio invalido para el grupo %s: %v", chatIDStr, err)
+						c.reply(chatID, errInvalidStart, nil)
+						return
+					}
+					start = now.Add(delay)
+				}
+
+				group.ScheduledStart = start.Unix()
+				group.ScheduledDuration = int64(duration.Seconds())
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, schedulecomp, strings.Join(parts, " "))
+				c.reply(chatID, compScheduled, &schedule{Start: start.Unix(), Duration: duration})
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case language:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				c.reply(chatID, languagePrivate, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				status := &languageStatus{
+					Current:   group.Locale,
+					Available: strings.Join(c.Messages.Locales(), ", "),
+				}
+
+				parts := strings.Fields(param)
+				if len(parts) < 2 {
+					c.reply(chatID, usageLanguage, status)
+					return
+				}
+
+				locale := messages.Normalize(parts[1])
+				if !c.Messages.Supported(locale) {
+					c.reply(chatID, errInvalidLanguage, status)
+					return
+				}
+
+				group.Locale = locale
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, language, locale)
+				c.reply(chatID, languageUpdated, nil)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case audit:
+			// En privado /audit es el registro global de los administradores del bot
+			if chat.IsPrivate() {
+				return
+			}
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				c.send(chatID, c.auditMessage(chatID, chatIDStr))
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case admin, addadmin, removeadmin, listAdmins, addcampaign, removecampaign, listCampaigns:
+			return
+		case "ca":
+			return
+		case removebuyer:
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, update.Message.Command()) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, update.Message.Command())
+				c.reply(chatID, roleRequired, requiredRole(update.Message.Command()))
+				return
+			}
+
+			if exist {
+				parts := strings.SplitN(param, " ", 2)
+				if len(parts) < 2 {
+					c.reply(chatID, "usage_remove_buyer", nil)
+					return
+				}
+
+				buyerAddress := parts[1]
+
+				purchase, err := c.Comps.GetComp(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener la competencia para el grupo %s", chatIDStr)
+					return
+				}
+				_, hash, err := purchase.GetPurchaseAndHashByBuyer(buyerAddress)
+				if err != nil {
+					log.Printf("no se pudo obtener la compra para el grupo %s", chatIDStr)
+					return
+				}
+
+				err = purchase.RemovePurchase(hash)
+				if err != nil {
+					log.Printf("no se pudo remover la compra para el grupo %s", chatIDStr)
+					return
+				}
+
+				c.events.MarkBoard(chatIDStr)
+				c.audit(update.Message, chatIDStr, removebuyer, buyerAddress)
+				c.reply(chatID, purchaseRemoved, nil)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		case setrole, listRoles:
+			c.handleRoles(update, &chat)
+			return
+		case advertise:
+			c.handleAdvertise(update)
+			return
+		default:
+			c.defaultHandler(update)
+			return
+		}
+	}
+}
+
+func (c *Commands) handleNoCommands(update tgbotapi.Update) {
+	chatIDStr := strconv.FormatInt(update.Message.Chat.ID, 10)
+	chatID := update.Message.Chat.ID
+
+	exist := c.Groups.GroupExist(chatIDStr)
+
+	chatConfig := tgbotapi.ChatConfig{ChatID: chatID}
+	chat, err := c.BotAPI.GetChat(chatConfig)
+	if err != nil {
+		log.Println(err)
+	}
+
+	if update.Message.From.ID == c.BotAPI.Self.ID {
+		return
+	}
+
+	if !update.Message.IsCommand() && !chat.IsPrivate() {
+		userID := update.Message.From.ID
+
+		temps, err := c.Temps.GetActiveTemps(chatIDStr)
+		if err != nil {
+			log.Printf("error cargando los temps del grupo %s: %s", chatIDStr, err)
+			return
+		}
+
+		if temps.AwaitingToken {
+			param := update.Message.Text
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, addtoken) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, addtoken)
+				c.reply(chatID, roleRequired, requiredRole(addtoken))
+				return
+			}
+
+			if exist {
+				isAddress, err := getters.IsAddress(param)
+				if err != nil {
+					log.Printf("no se pudo comprobar si el parametro era una direccion")
+					c.reply(chatID, errorUnexpected, nil)
+					return
+				}
+
+				if isAddress {
+
+					group, err := c.Groups.GetDataGroup(chatIDStr)
+					if err != nil {
+						log.Printf("no se pudo obtener los datos del grupo")
+						return
+					}
+
+					if group.JettonAddress != "" {
+						log.Printf("el grupo %s, ya tiene una direccion activa", chatIDStr)
+						c.reply(chatID, errorJettonAlreadyAdded, nil)
+						return
+					}
+
+					group.JettonAddress = param
+
+					err = c.Groups.AddPools(chatIDStr)
+					if err != nil {
+						log.Printf("no se pudo obtener los pools, %v", err)
+						return
+					}
+
+					err = c.Temps.ChangeTemp(0, chatIDStr, false)
+					if err != nil {
+						log.Printf("no se pudo actualizar el temp, %v", err)
+						return
+					}
+
+					c.saveGroup(chatIDStr)
+					c.audit(update.Message, chatIDStr, addtoken, param)
+					c.reply(chatID, tokenAdded, nil)
+					return
+				} else {
+					log.Printf("direccion de jetton invalida para el grupo %s", chatIDStr)
+					c.reply(chatID, invalidJetton, nil)
+					return
+				}
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+
+		}
+
+		if temps.AwaitingEmoji {
+			param := update.Message.Text
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, addemoji) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, addemoji)
+				c.reply(chatID, roleRequired, requiredRole(addemoji))
+				return
+			}
+
+			if exist {
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo")
+					return
+				}
+
+				group.Emoji = param
+
+				err = c.Temps.ChangeTemp(1, chatIDStr, false)
+				if err != nil {
+					log.Printf("no se pudo actualizar el temp, %v", err)
+					return
+				}
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, addemoji, param)
+				c.reply(chatID, emojiAdded, nil)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+
+		}
+
+		if temps.AwaitingTimestamp {
+			param := update.Message.Text
+
+			if !chat.IsGroup() && !chat.IsSuperGroup() {
+				log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+				c.reply(chatID, onlyGroups, nil)
+				return
+			}
+
+			if !c.allowed(userID, chatID, startnewcomp) {
+				log.Printf("el usuario %s no tiene el rol necesario para %s", update.Message.From.UserName, startnewcomp)
+				c.reply(chatID, roleRequired, requiredRole(startnewcomp))
+				return
+			}
+
+			if exist {
+				timestamp, err := core.ParseEndTime(param, time.Now())
+				if err != nil {
+					log.Printf("parametro invalido para comenzar la competencia: %v", err)
+					c.reply(chatID, errInvalidFormatHours, nil)
+					return
+				}
+
+				err = c.events.StartCompetition(chatIDStr, timestamp)
+				if err != nil {
+					log.Printf("no se pudo iniciar la competencia para el grupo %s: %v", chatIDStr, err)
+					return
+				}
+
+				err = c.Temps.ChangeTemp(2, chatIDStr, false)
+				if err != nil {
+					log.Printf("no se pudo actualizar el temp, %v", err)
+					return
+				}
+
+				group, err := c.Groups.GetDataGroup(chatIDStr)
+				if err != nil {
+					log.Printf("no se pudo obtener los datos del grupo, %v", err)
+					return
+				}
+
+				c.saveGroup(chatIDStr)
+				c.audit(update.Message, chatIDStr, startnewcomp, param)
+				c.reply(chatID, "comp_started", &notificator.CompetitionStarted{Group: group, EndTime: timestamp})
+				c.events.PostBoard(chatIDStr)
+				return
+			} else {
+				c.reply(chatID, initGroup, nil)
+				return
+			}
+		}
+	}
+}
+
+func (b *Commands) defaultHandler(update tgbotapi.Update) {
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.render(update.Message.Chat.ID, "unknown_message", nil))
+	b.Sender.Send(update.Message.Chat.ID, msg)
+}
+
+// messageMedia devuelve el file_id y el tipo de la media de un mensaje, vacio si no tiene una media admitida
+func messageMedia(message *tgbotapi.Message) (string, string) {
+	if message == nil {
+		return "", ""
+	}
+
+	switch {
+	case message.Animation != nil:
+		return message.Animation.FileID, promotions.MediaAnimation
+	case message.Video != nil:
+		return message.Video.FileID, promotions.MediaVideo
+	case message.Photo != nil && len(*message.Photo) > 0:
+		photos := *message.Photo
+		return photos[len(photos)-1].FileID, promotions.MediaPhoto
+	default:
+		return "", ""
+	}
+}
+
+// parseWindow interpreta la ventana de /burst, como 60s, 2m o un numero de segundos, hasta una hora
+func parseWindow(value string) (time.Duration, error) {
+	if window, err := time.ParseDuration(value); err == nil && window >= time.Second && window <= time.Hour {
+		return window, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds > 0 && seconds <= 3600 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return 0, errors.New("ventana invalida")
+}
+
+// remindersStatus describe los avisos configurados, como "24h, 6h, 1h, 10m"
+func (c *Commands) remindersStatus(chatID int64, value string) string {
+	list, err := core.ParseReminders(value)
+	if err != nil || len(list) == 0 {
+		return c.render(chatID, "reminders_status", "")
 	}
 
-	return header
+	return c.render(chatID, "reminders_status", strings.ReplaceAll(core.FormatReminders(list), ",", ", "))
+}
+
+// saveEndTime guarda en la base de datos la fecha de culminacion y la pausa tras cada cambio
+func (c *Commands) saveEndTime(chatID string) {
+	if c.DB == nil {
+		return
+	}
+
+	timestamp, err := c.Comps.GetTimestamp(chatID)
+	if err != nil {
+		log.Printf("no se pudo obtener el timestamp para el grupo %s: %v", chatID, err)
+		return
+	}
+
+	err = database.WriteEndTime(c.DB, chatID, timestamp, c.Comps.PausedAt(chatID))
+	if err != nil {
+		log.Printf("no se pudo guardar el timestamp para el grupo %s: %v", chatID, err)
+	}
+}
+
+// timeChange son los datos de los avisos de /extend, /shorten, /pause y /resume
+type timeChange struct {
+	Delta    string
+	TimeLeft time.Duration
+}
+
+type schedule struct {
+	Start    int64
+	Duration time.Duration
+}
+
+type languageStatus struct {
+	Current   string
+	Available string
+}
+
+type burstSettings struct {
+	Threshold int64
+	Window    time.Duration
+}
+
+// competitionRules son los datos del mensaje de /rules
+type competitionRules struct {
+	Group             *groups.GroupData
+	EndTime           int64
+	Paused            bool
+	TimeLeft          time.Duration
+	ScheduledDuration time.Duration
+	Reminders         string
+}
+
+func (c *Commands) rulesMessage(chatID int64, group *groups.GroupData) string {
+	data := &competitionRules{
+		Group:             group,
+		ScheduledDuration: time.Duration(group.ScheduledDuration) * time.Second,
+	}
+
+	if group.CompActive {
+		timestamp, err := c.Comps.GetTimestamp(group.ID)
+		if err == nil {
+			data.EndTime = timestamp
+		}
+		data.Paused = c.Comps.IsPaused(group.ID)
+		data.TimeLeft = c.Comps.TimeLeft(group.ID)
+	}
+
+	list, err := core.ParseReminders(group.Reminders)
+	if err == nil && len(list) > 0 {
+		data.Reminders = strings.ReplaceAll(core.FormatReminders(list), ",", ", ")
+	}
+
+	return c.render(chatID, "competition_rules", data)
+}
+
+func userTag(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+
+	return user.FirstName
+}
+
+// stopCompetition termina la competencia del grupo y lo anuncia, la usan /stopcomp y el panel de administracion
+func (c *Commands) stopCompetition(chatIDStr string) {
+	chatID, _ := strconv.ParseInt(chatIDStr, 10, 64)
+
+	c.events.CloseBoard(chatIDStr)
+
+	err := c.events.RemoveTicker(chatIDStr)
+	if err != nil {
+		log.Printf("no se pudo remover el ticker para el grupo %s, %v", chatIDStr, err)
+	}
+
+	err = c.Comps.RemoveBlacklistActive(chatIDStr)
+	if err != nil {
+		log.Printf("no se pudo remover el blacklist para el grupo %s", chatIDStr)
+	}
+
+	err = c.Comps.RemoveTimestampActive(chatIDStr)
+	if err != nil {
+		log.Printf("no se pudo remover el timestamp para el grupo %s", chatIDStr)
+	}
+
+	err = c.Groups.UpdateCompStatus(chatIDStr, false)
+	if err != nil {
+		log.Printf("no se pudo remover el status para el grupo %s", chatIDStr)
+	}
+
+	c.events.RemoveCompetitionData(chatIDStr)
+	c.saveGroup(chatIDStr)
+	c.Sender.SendPriority(chatID, tgbotapi.NewMessage(chatID, c.render(chatID, competitionEnded, nil)))
+}
+
+// saveGroup guarda la configuracion del grupo de inmediato, sin esperar al siguiente backup
+func (c *Commands) saveGroup(chatIDStr string) {
+	if c.DB == nil {
+		return
+	}
+
+	group, err := c.Groups.GetDataGroup(chatIDStr)
+	if err != nil {
+		return
+	}
+
+	err = database.WriteGroups(c.DB, group)
+	if err != nil {
+		log.Printf("no se pudo guardar el grupo %s: %v", chatIDStr, err)
+	}
+}
+
+// topBuyers completa la lista hasta los 10 puestos, los puestos vacios se muestran como not set
+func topBuyers(buyers []*core.Purchase) []*core.Purchase {
+	top := make([]*core.Purchase, 10)
+	copy(top, buyers)
+
+	return top
 }
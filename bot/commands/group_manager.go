@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/database"
+)
+
+const (
+	// groupsPrefix es el prefijo de los callbacks de la pantalla de grupos, el resto es accion:valor
+	groupsPrefix  = "grp:"
+	groupsPage    = "page"
+	groupsView    = "view"
+	groupsBoard   = "board"
+	groupsStop    = "stop"
+	groupsToggle  = "toggle"
+	groupsRemove  = "remove"
+	groupsConfirm = "confirm"
+
+	// groupsPerPage es la cantidad de grupos de cada pagina
+	groupsPerPage = 8
+
+	groupsList            = "groups_page"
+	groupDetail           = "group_detail"
+	groupButtonBoard      = "group_button_board"
+	groupButtonStop       = "group_button_stop"
+	groupButtonDisable    = "group_button_disable"
+	groupButtonEnable     = "group_button_enable"
+	groupButtonRemove     = "group_button_remove"
+	groupButtonConfirm    = "group_button_confirm"
+	groupButtonBack       = "group_button_back"
+	groupsButtonPrev      = "groups_button_prev"
+	groupsButtonNext      = "groups_button_next"
+	groupRemoveConfirm    = "group_remove_confirm"
+	groupRemoved          = "group_removed"
+	groupDisabled         = "group_disabled"
+	errGroupNotFound      = "err_group_not_found"
+	errGroupCompNotActive = "err_group_comp_not_active"
+)
+
+// groupSummary son los datos de un grupo que se muestran en la lista y en el detalle
+type groupSummary struct {
+	ID       string
+	Title    string
+	Jetton   string
+	Active   bool
+	Paused   bool
+	EndTime  int64
+	Disabled bool
+}
+
+// groupsPageData es una pagina de la lista, Page empieza en 0
+type groupsPageData struct {
+	Page   int
+	Pages  int
+	Total  int
+	Groups []*groupSummary
+}
+
+// groupDisabled responde a los comandos de un grupo deshabilitado, devuelve true si el mensaje se debe ignorar
+func (c *Commands) groupDisabled(message *tgbotapi.Message) bool {
+	group, err := c.Groups.GetDataGroup(strconv.FormatInt(message.Chat.ID, 10))
+	if err != nil || !group.Disabled {
+		return false
+	}
+
+	if message.IsCommand() {
+		c.reply(message.Chat.ID, groupDisabled, nil)
+	}
+
+	return true
+}
+
+// enabledGroups devuelve los grupos registrados que no fueron deshabilitados
+func (c *Commands) enabledGroups() []string {
+	ids := make([]string, 0)
+	for _, id := range c.Groups.IDs() {
+		group, err := c.Groups.GetDataGroup(id)
+		if err != nil || group.Disabled {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// groupSummary arma los datos del grupo, el nombre se pide a Telegram y queda vacio si el bot ya no esta en el grupo
+func (c *Commands) groupSummary(id string) (*groupSummary, error) {
+	group, err := c.Groups.GetDataGroup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &groupSummary{
+		ID:       id,
+		Jetton:   group.JettonAddress,
+		Active:   group.CompActive,
+		Disabled: group.Disabled,
+	}
+
+	chatID, _ := strconv.ParseInt(id, 10, 64)
+	chat, err := c.BotAPI.GetChat(tgbotapi.ChatConfig{ChatID: chatID})
+	if err != nil {
+		log.Printf("no se pudo obtener el chat del grupo %s: %v", id, err)
+	} else {
+		summary.Title = chat.Title
+	}
+
+	if group.CompActive {
+		summary.Paused = c.Comps.IsPaused(id)
+		summary.EndTime, _ = c.Comps.GetTimestamp(id)
+	}
+
+	return summary, nil
+}
+
+// groupLabel es el texto del boton del grupo en la lista. Se arma sin plantilla porque los botones no usan HTML.
+func groupLabel(summary *groupSummary) string {
+	status := "⚪"
+	switch {
+	case summary.Disabled:
+		status = "⛔"
+	case summary.Paused:
+		status = "⏸"
+	case summary.Active:
+		status = "🟢"
+	}
+
+	if summary.Title == "" {
+		return status + " " + summary.ID
+	}
+
+	return status + " " + summary.Title
+}
+
+func groupsCallback(action string, value string) string {
+	return groupsPrefix + action + ":" + value
+}
+
+// groupsPageView arma la pagina de la lista de grupos con un boton por grupo y la navegacion
+func (c *Commands) groupsPageView(chatID int64, page int) (string, tgbotapi.InlineKeyboardMarkup) {
+	ids := c.Groups.IDs()
+
+	data := &groupsPageData{Total: len(ids), Pages: (len(ids) + groupsPerPage - 1) / groupsPerPage}
+
+	if page >= data.Pages {
+		page = data.Pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	data.Page = page
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+
+	start := page * groupsPerPage
+	end := start + groupsPerPage
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	for _, id := range ids[start:end] {
+		summary, err := c.groupSummary(id)
+		if err != nil {
+			continue
+		}
+
+		data.Groups = append(data.Groups, summary)
+
+		button := tgbotapi.NewInlineKeyboardButtonData(groupLabel(summary), groupsCallback(groupsView, id))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+	}
+
+	navigation := make([]tgbotapi.InlineKeyboardButton, 0, 2)
+	if page > 0 {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupsButtonPrev, nil), groupsCallback(groupsPage, strconv.Itoa(page-1))))
+	}
+	if page < data.Pages-1 {
+		navigation = append(navigation, tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupsButtonNext, nil), groupsCallback(groupsPage, strconv.Itoa(page+1))))
+	}
+	if len(navigation) > 0 {
+		rows = append(rows, navigation)
+	}
+
+	return c.render(chatID, groupsList, data), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// groupDetailView arma el detalle del grupo con sus acciones
+func (c *Commands) groupDetailView(chatID int64, summary *groupSummary) (string, tgbotapi.InlineKeyboardMarkup) {
+	board := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonBoard, nil), groupsCallback(groupsBoard, summary.ID))
+	stop := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonStop, nil), groupsCallback(groupsStop, summary.ID))
+
+	toggleText := groupButtonDisable
+	if summary.Disabled {
+		toggleText = groupButtonEnable
+	}
+
+	toggle := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, toggleText, nil), groupsCallback(groupsToggle, summary.ID))
+	remove := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonRemove, nil), groupsCallback(groupsRemove, summary.ID))
+	back := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonBack, nil), groupsCallback(groupsPage, "0"))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(board, stop),
+		tgbotapi.NewInlineKeyboardRow(toggle, remove),
+		tgbotapi.NewInlineKeyboardRow(back),
+	)
+
+	return c.render(chatID, groupDetail, summary), markup
+}
+
+// sendGroups envia la primera pagina de la lista de grupos
+func (c *Commands) sendGroups(chatID int64) {
+	text, markup := c.groupsPageView(chatID, 0)
+	c.sendReplyWithMarkup(chatID, text, &markup)
+}
+
+// editGroups reemplaza la pantalla de grupos en el mismo mensaje
+func (c *Commands) editGroups(chatID int64, messageID int, text string, markup tgbotapi.InlineKeyboardMarkup) {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = messages.ParseMode
+	edit.ReplyMarkup = &markup
+	c.Sender.Send(chatID, edit)
+}
+
+// removeGroup quita el grupo con su competencia, roles y exclusiones, y el bot sale del grupo
+func (c *Commands) removeGroup(id string) {
+	// Los errores solo indican que el grupo no tenia esos datos
+	c.events.RemoveTicker(id)
+	c.Comps.RemoveCompActive(id)
+	c.Comps.RemoveBlacklistActive(id)
+	c.Comps.RemoveTimestampActive(id)
+	c.Roles.Clear(id)
+	c.Exclusions.Clear(id)
+	c.Temps.RemoveTemp(id)
+
+	err := c.Groups.RemoveGroup(id)
+	if err != nil {
+		log.Printf("no se pudo remover el grupo %s: %v", id, err)
+	}
+
+	if c.DB != nil {
+		err = database.RemoveGroupData(c.DB, id)
+		if err != nil {
+			log.Printf("no se pudo remover el grupo %s de la base de datos: %v", id, err)
+		}
+	}
+
+	chatID, _ := strconv.ParseInt(id, 10, 64)
+	_, err = c.BotAPI.LeaveChat(tgbotapi.ChatConfig{ChatID: chatID})
+	if err != nil {
+		log.Printf("no se pudo salir del grupo %s: %v", id, err)
+	}
+
+	log.Printf("grupo con ID: %s, removido desde el panel", id)
+}
+
+// handleGroupManager atiende los botones de la pantalla de grupos, solo para administradores con sesion abierta
+func (c *Commands) handleGroupManager(callbackQuery *tgbotapi.CallbackQuery) {
+	if callbackQuery.Message == nil {
+		return
+	}
+
+	chatID := callbackQuery.Message.Chat.ID
+	messageID := callbackQuery.Message.MessageID
+	userID := int64(callbackQuery.From.ID)
+
+	err := c.Admins.Touch(userID)
+	if errors.Is(err, errAdminSessionExpired) {
+		c.hideKeyboard(chatID, errSessionExpired)
+		return
+	}
+	if err != nil || !c.Admins.IsLogged(userID) {
+		c.reply(chatID, errNoLoggued, nil)
+		return
+	}
+
+	action, value, _ := strings.Cut(strings.TrimPrefix(callbackQuery.Data, groupsPrefix), ":")
+
+	if action == groupsPage {
+		page, _ := strconv.Atoi(value)
+		text, markup := c.groupsPageView(chatID, page)
+		c.editGroups(chatID, messageID, text, markup)
+		return
+	}
+
+	group, err := c.Groups.GetDataGroup(value)
+	if err != nil {
+		c.reply(chatID, errGroupNotFound, nil)
+		return
+	}
+
+	actor := &tgbotapi.Message{From: callbackQuery.From}
+
+	switch action {
+	case groupsBoard:
+		order, err := c.Comps.GetComp(value)
+		if err != nil || len(order.GetCompList()) == 0 {
+			c.reply(chatID, emptyList, nil)
+			return
+		}
+
+		c.reply(chatID, "top_buyers", topBuyers(order.GetCompList()))
+		return
+	case groupsStop:
+		if !group.CompActive {
+			c.reply(chatID, errGroupCompNotActive, nil)
+			return
+		}
+
+		c.stopCompetition(value)
+		c.audit(actor, value, "forcestop", "")
+	case groupsToggle:
+		err := c.Groups.SetDisabled(value, !group.Disabled)
+		if err != nil {
+			log.Printf("no se pudo cambiar el estado del grupo %s: %v", value, err)
+			return
+		}
+
+		c.saveGroup(value)
+
+		if group.Disabled {
+			c.audit(actor, value, "disablegroup", "")
+		} else {
+			c.audit(actor, value, "enablegroup", "")
+		}
+	case groupsRemove:
+		summary, err := c.groupSummary(value)
+		if err != nil {
+			return
+		}
+
+		confirm := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonConfirm, nil), groupsCallback(groupsConfirm, value))
+		back := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonBack, nil), groupsCallback(groupsView, value))
+
+		markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(confirm, back))
+		c.editGroups(chatID, messageID, c.render(chatID, groupRemoveConfirm, summary), markup)
+		return
+	case groupsConfirm:
+		// Se registra en el scope global porque el grupo y sus datos dejan de existir
+		c.audit(actor, core.GlobalScope, "removegroup", value)
+		c.removeGroup(value)
+
+		back := tgbotapi.NewInlineKeyboardButtonData(c.render(chatID, groupButtonBack, nil), groupsCallback(groupsPage, "0"))
+		c.editGroups(chatID, messageID, c.render(chatID, groupRemoved, value), tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(back)))
+		return
+	case groupsView:
+	default:
+		return
+	}
+
+	summary, err := c.groupSummary(value)
+	if err != nil {
+		return
+	}
+
+	text, markup := c.groupDetailView(chatID, summary)
+	c.editGroups(chatID, messageID, text, markup)
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+)
+
+// locale devuelve el idioma de un chat: el configurado con /language en los grupos o el del usuario en un chat privado
+func (c *Commands) locale(chatID int64) string {
+	group, err := c.Groups.GetDataGroup(strconv.FormatInt(chatID, 10))
+	if err == nil && group.Locale != "" {
+		return group.Locale
+	}
+
+	c.localesMutex.RLock()
+	defer c.localesMutex.RUnlock()
+
+	locale, exist := c.locales[chatID]
+	if exist {
+		return locale
+	}
+
+	return messages.DefaultLocale
+}
+
+// rememberLocale guarda el language_code que Telegram envia con cada mensaje de un chat privado
+func (c *Commands) rememberLocale(message *tgbotapi.Message) {
+	if message.From == nil || message.Chat == nil || !message.Chat.IsPrivate() || message.From.LanguageCode == "" {
+		return
+	}
+
+	locale := messages.Normalize(message.From.LanguageCode)
+	if !c.Messages.Supported(locale) {
+		locale = messages.DefaultLocale
+	}
+
+	c.localesMutex.Lock()
+	defer c.localesMutex.Unlock()
+
+	c.locales[message.Chat.ID] = locale
+}
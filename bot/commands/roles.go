@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+const (
+	setrole   = "setrole"
+	listRoles = "roles"
+
+	usageSetRole   = "usage_set_role"
+	errInvalidRole = "err_invalid_role"
+	errRoleNotSet  = "err_role_not_set"
+	roleUpdated    = "role_updated"
+	roleRemoved    = "role_removed"
+	roleRequired   = "role_required"
+	groupRoles     = "group_roles"
+)
+
+// commandRoles es el rol minimo de cada comando de grupo, los que no aparecen requieren core.RoleManager
+var commandRoles = map[string]string{
+	addtoken:    core.RoleOwner,
+	removetoken: core.RoleOwner,
+	setrole:     core.RoleOwner,
+
+	removebuyer: core.RoleModerator,
+	exclude:     core.RoleModerator,
+	unexclude:   core.RoleModerator,
+	ban:         core.RoleModerator,
+	unban:       core.RoleModerator,
+	audit:       core.RoleModerator,
+	listRoles:   core.RoleModerator,
+}
+
+func requiredRole(command string) string {
+	role, exist := commandRoles[command]
+	if !exist {
+		return core.RoleManager
+	}
+
+	return role
+}
+
+// memberRole devuelve el rol del usuario en el grupo. El creador siempre es owner, despues se usa el rol
+// asignado con /setrole y los administradores del chat sin rol asignado son manager.
+func (c *Commands) memberRole(userID int, chatID int64) string {
+	memberConfig := tgbotapi.ChatConfigWithUser{
+		ChatID: chatID,
+		UserID: userID,
+	}
+	member, err := c.BotAPI.GetChatMember(memberConfig)
+	if err != nil {
+		log.Println(err)
+	}
+
+	if member.IsCreator() {
+		return core.RoleOwner
+	}
+
+	role, exist := c.Roles.Get(strconv.FormatInt(chatID, 10), int64(userID))
+	if exist {
+		return role
+	}
+
+	if member.IsAdministrator() {
+		return core.RoleManager
+	}
+
+	return core.RoleNone
+}
+
+func (c *Commands) allowed(userID int, chatID int64, command string) bool {
+	return core.RoleAllows(c.memberRole(userID, chatID), requiredRole(command))
+}
+
+// roleTarget devuelve el usuario y el rol de /setrole, respondiendo a un mensaje del usuario o con su id
+func roleTarget(message *tgbotapi.Message) (int64, string, string, bool) {
+	fields := strings.Fields(message.CommandArguments())
+
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil && len(fields) == 1 {
+		user := message.ReplyToMessage.From
+		return int64(user.ID), user.UserName, strings.ToLower(fields[0]), true
+	}
+
+	if len(fields) != 2 {
+		return 0, "", "", false
+	}
+
+	id, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, "", "", false
+	}
+
+	return id, "", strings.ToLower(fields[1]), true
+}
+
+func (c *Commands) handleRoles(update tgbotapi.Update, chat *tgbotapi.Chat) {
+	message := update.Message
+	chatID := message.Chat.ID
+	chatIDStr := strconv.FormatInt(chatID, 10)
+	userID := message.From.ID
+
+	if !chat.IsGroup() && !chat.IsSuperGroup() {
+		log.Printf("the current group %v, no es un grupo o un supergrupo", chatID)
+		c.reply(chatID, onlyGroups, nil)
+		return
+	}
+
+	command := message.Command()
+
+	if !c.allowed(userID, chatID, command) {
+		log.Printf("el usuario %s no tiene el rol necesario para %s", message.From.UserName, command)
+		c.reply(chatID, roleRequired, requiredRole(command))
+		return
+	}
+
+	switch command {
+	case setrole:
+		id, username, role, ok := roleTarget(message)
+		if !ok {
+			c.reply(chatID, usageSetRole, nil)
+			return
+		}
+
+		if !core.ValidRole(role) {
+			c.reply(chatID, errInvalidRole, nil)
+			return
+		}
+
+		err := c.Roles.Set(chatIDStr, id, username, role)
+		if err != nil {
+			log.Printf("no se pudo asignar el rol %s al usuario %d en el grupo %s: %v", role, id, chatIDStr, err)
+			c.reply(chatID, errRoleNotSet, nil)
+			return
+		}
+
+		c.audit(message, chatIDStr, setrole, strconv.FormatInt(id, 10)+" "+role)
+
+		assignment := core.RoleAssignment{UserID: id, Username: username, Role: role}
+		if role == core.RoleNone {
+			c.reply(chatID, roleRemoved, assignment)
+			return
+		}
+
+		c.reply(chatID, roleUpdated, assignment)
+	case listRoles:
+		c.reply(chatID, groupRoles, c.Roles.List(chatIDStr))
+	}
+}
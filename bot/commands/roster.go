@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/database"
+)
+
+const (
+	addadmin    = "addadmin"
+	removeadmin = "removeadmin"
+	listAdmins  = "admins"
+
+	usageAddAdmin        = "usage_add_admin"
+	usageRemoveAdmin     = "usage_remove_admin"
+	errSuperAdminOnly    = "err_super_admin_only"
+	errAdminAlreadyAdded = "err_admin_already_added"
+	errAdminNotFound     = "err_admin_not_found"
+	errRemoveSuperAdmin  = "err_remove_super_admin"
+	adminAdded           = "admin_added"
+	adminRemoved         = "admin_removed"
+	adminRoster          = "admin_roster"
+)
+
+var errNoAdminTarget = errors.New("error: no se indico el usuario")
+
+// LoadAdmins carga los administradores guardados y aplica los super administradores de la configuracion.
+// El rol de super administrador solo lo tienen los ids de la configuracion, al resto se les quita.
+func (c *Commands) LoadAdmins(superAdmins []int64) {
+	supers := make(map[int64]struct{}, len(superAdmins))
+	for _, id := range superAdmins {
+		supers[id] = struct{}{}
+	}
+
+	if c.DB != nil {
+		entries, err := database.GetAdmins(c.DB)
+		if err != nil {
+			log.Printf("no se pudieron cargar los administradores del bot: %v", err)
+		}
+
+		for _, entry := range entries {
+			role := entry.Role
+			if _, super := supers[entry.UserID]; !super && role == RoleSuperAdmin {
+				role = RoleAdmin
+			}
+
+			err := c.Admins.AddAdmin(entry.UserID, entry.Username, role)
+			if err != nil {
+				log.Printf("no se pudo cargar el administrador %d: %v", entry.UserID, err)
+				continue
+			}
+
+			if role != entry.Role {
+				c.saveAdmin(entry.UserID, 0)
+			}
+		}
+	}
+
+	for _, id := range superAdmins {
+		admin, err := c.Admins.GetAdmin(id)
+		if err == nil && admin.Role == RoleSuperAdmin {
+			continue
+		}
+
+		if err != nil {
+			err = c.Admins.AddAdmin(id, "", RoleSuperAdmin)
+		} else {
+			err = c.Admins.UpdateAdmin(id, admin.Username, RoleSuperAdmin)
+		}
+		if err != nil {
+			log.Printf("no se pudo registrar el super administrador %d: %v", id, err)
+			continue
+		}
+
+		c.saveAdmin(id, 0)
+	}
+
+	if len(superAdmins) == 0 {
+		log.Print("no hay super administradores configurados, define SUPER_ADMINS para poder gestionar los administradores")
+	}
+}
+
+// saveAdmin guarda el administrador en la base de datos
+func (c *Commands) saveAdmin(id int64, addedBy int64) {
+	if c.DB == nil {
+		return
+	}
+
+	admin, err := c.Admins.GetAdmin(id)
+	if err != nil {
+		return
+	}
+
+	entry := &database.AdminEntry{
+		UserID:    admin.ID,
+		Username:  admin.Username,
+		Role:      admin.Role,
+		AddedBy:   addedBy,
+		Timestamp: time.Now().Unix(),
+	}
+
+	err = database.WriteAdmin(c.DB, entry)
+	if err != nil {
+		log.Printf("no se pudo guardar el administrador %d: %v", id, err)
+	}
+}
+
+// refreshAdmin actualiza el usuario guardado si el administrador lo cambio en Telegram
+func (c *Commands) refreshAdmin(user *tgbotapi.User) {
+	if user == nil {
+		return
+	}
+
+	id := int64(user.ID)
+
+	admin, err := c.Admins.GetAdmin(id)
+	if err != nil || admin.Username == user.UserName {
+		return
+	}
+
+	err = c.Admins.UpdateAdmin(id, user.UserName, "")
+	if err != nil {
+		return
+	}
+
+	c.saveAdmin(id, 0)
+}
+
+// adminTarget devuelve el usuario al que se responde con el comando o el id pasado como argumento
+func adminTarget(message *tgbotapi.Message) (int64, string, error) {
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil {
+		return int64(message.ReplyToMessage.From.ID), message.ReplyToMessage.From.UserName, nil
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, "", errNoAdminTarget
+	}
+
+	return id, "", nil
+}
+
+func (c *Commands) handleRoster(update tgbotapi.Update) {
+	message := update.Message
+	chatID := message.Chat.ID
+	userID := int64(message.From.ID)
+
+	if !c.Admins.IsSuperAdmin(userID) {
+		// A los usuarios que no son administradores no se les responde para no llenar los grupos de mensajes
+		if c.Admins.AdminExist(userID) {
+			c.reply(chatID, errSuperAdminOnly, nil)
+		}
+		return
+	}
+
+	switch message.Command() {
+	case addadmin:
+		id, username, err := adminTarget(message)
+		if err != nil {
+			c.reply(chatID, usageAddAdmin, nil)
+			return
+		}
+
+		err = c.Admins.AddAdmin(id, username, RoleAdmin)
+		if err != nil {
+			log.Printf("no se pudo agregar el administrador %d: %v", id, err)
+			c.reply(chatID, errAdminAlreadyAdded, nil)
+			return
+		}
+
+		c.saveAdmin(id, userID)
+		c.audit(message, core.GlobalScope, addadmin, strconv.FormatInt(id, 10))
+
+		admin, _ := c.Admins.GetAdmin(id)
+		c.reply(chatID, adminAdded, admin)
+	case removeadmin:
+		id, _, err := adminTarget(message)
+		if err != nil {
+			c.reply(chatID, usageRemoveAdmin, nil)
+			return
+		}
+
+		admin, err := c.Admins.GetAdmin(id)
+		if err != nil {
+			c.reply(chatID, errAdminNotFound, nil)
+			return
+		}
+
+		// Los super administradores se gestionan desde la configuracion
+		if admin.Role == RoleSuperAdmin {
+			c.reply(chatID, errRemoveSuperAdmin, nil)
+			return
+		}
+
+		err = c.Admins.RemoveAdmin(id)
+		if err != nil {
+			log.Printf("no se pudo quitar el administrador %d: %v", id, err)
+			c.reply(chatID, errAdminNotFound, nil)
+			return
+		}
+
+		if c.DB != nil {
+			_, err = database.RemoveAdminData(c.DB, id)
+			if err != nil {
+				log.Printf("no se pudo borrar el administrador %d de la base de datos: %v", id, err)
+			}
+		}
+
+		c.audit(message, core.GlobalScope, removeadmin, strconv.FormatInt(id, 10))
+		c.reply(chatID, adminRemoved, admin)
+	case listAdmins:
+		c.reply(chatID, adminRoster, c.Admins.List())
+	}
+}
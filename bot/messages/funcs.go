@@ -0,0 +1,107 @@
+package messages
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math/big"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+// funcs son las funciones disponibles en todas las plantillas
+var funcs = template.FuncMap{
+	"short":    ShortWallet,
+	"wallet":   func(wallet string) template.HTML { return template.HTML(WalletLink(wallet)) },
+	"amount":   Amount,
+	"duration": core.FormatDuration,
+	"clock":    clock,
+	"date":     date,
+	"inc":      func(i int) int { return i + 1 },
+	"medal":    medal,
+}
+
+// ShortWallet acorta una direccion a sus primeros y ultimos 6 caracteres
+func ShortWallet(wallet string) string {
+	if len(wallet) <= 12 {
+		return wallet
+	}
+
+	return fmt.Sprintf("%s...%s", wallet[:6], wallet[len(wallet)-6:])
+}
+
+// WalletLink enlaza la direccion acortada a tonviewer, devuelve HTML listo para enviar
+func WalletLink(wallet string) string {
+	return fmt.Sprintf(`<a href="https://tonviewer.com/%s/">%s</a>`, url.QueryEscape(wallet), Escape(ShortWallet(wallet)))
+}
+
+// Escape escapa un texto para incluirlo en un mensaje HTML armado fuera de las plantillas
+func Escape(text string) string {
+	return html.EscapeString(text)
+}
+
+// Amount da formato con separadores de miles a un monto entero
+func Amount(value interface{}) string {
+	var number string
+
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return "0"
+		}
+		number = v.String()
+	case int64:
+		number = strconv.FormatInt(v, 10)
+	case int:
+		number = strconv.Itoa(v)
+	default:
+		return fmt.Sprint(value)
+	}
+
+	sign := ""
+	if len(number) > 0 && number[0] == '-' {
+		sign, number = "-", number[1:]
+	}
+
+	formatted := ""
+	for i, digit := range number {
+		if i > 0 && (len(number)-i)%3 == 0 {
+			formatted += ","
+		}
+		formatted += string(digit)
+	}
+
+	return sign + formatted
+}
+
+// Clock es el tiempo restante separado en horas, minutos y segundos, cada idioma lo muestra con su plantilla countdown
+type Clock struct {
+	Hours   int
+	Minutes int
+	Seconds int
+}
+
+func clock(duration time.Duration) Clock {
+	return Clock{
+		Hours:   int(duration.Hours()),
+		Minutes: int(duration.Minutes()) % 60,
+		Seconds: int(duration.Seconds()) % 60,
+	}
+}
+
+// date muestra un timestamp en UTC
+func date(timestamp int64) string {
+	return time.Unix(timestamp, 0).UTC().Format("2006-01-02 15:04 UTC")
+}
+
+func medal(index int) string {
+	medals := []string{"🥇", "🥈", "🥉"}
+	if index < len(medals) {
+		return medals[index]
+	}
+
+	return fmt.Sprintf("%d.", index+1)
+}
@@ -0,0 +1,197 @@
+package messages
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultDir es el directorio donde se buscan las plantillas que reemplazan a las incluidas en el binario
+	DefaultDir = "assets/templates"
+
+	// DefaultLocale es el idioma de los grupos nuevos y el que se usa cuando falta un mensaje en otro idioma
+	DefaultLocale = "en"
+
+	// ParseMode es el formato de todos los mensajes generados con las plantillas
+	ParseMode = "HTML"
+)
+
+//go:embed templates
+var defaults embed.FS
+
+// Templates contiene todos los mensajes del bot por idioma. Cada mensaje es un template con nombre definido con {{define}}.
+// Desde el paso a ParseMode HTML se usa html/template en lugar de text/template, asi los datos de usuarios como nombres
+// de jettons, usernames o textos de anuncios salen escapados. Los textos que ya son HTML confiable se pasan como template.HTML.
+type Templates struct {
+	sets  map[string]*template.Template
+	mutex sync.RWMutex
+}
+
+// Load carga los catalogos incluidos en el binario, uno por subdirectorio de templates con el codigo del idioma,
+// y encima los de dir. Los archivos de dir reemplazan al idioma por defecto y los de dir/<idioma> a ese idioma,
+// asi se puede cambiar el texto o agregar un idioma sin recompilar.
+func Load(dir string) (*Templates, error) {
+	base, err := template.New("messages").Funcs(funcs).ParseFS(defaults, path.Join("templates", DefaultLocale, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	locales, err := fs.ReadDir(defaults, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	sets := map[string]*template.Template{DefaultLocale: base}
+
+	// Cada idioma parte de una copia del idioma por defecto, los mensajes sin traducir quedan en ingles
+	for _, locale := range locales {
+		if !locale.IsDir() || locale.Name() == DefaultLocale {
+			continue
+		}
+
+		set, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+
+		set, err = set.ParseFS(defaults, path.Join("templates", locale.Name(), "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		sets[locale.Name()] = set
+	}
+
+	if dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			err = loadOverrides(sets, dir)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Templates{sets: sets}, nil
+}
+
+func loadOverrides(sets map[string]*template.Template, dir string) error {
+	overrides, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return err
+	}
+
+	// Los {{define}} de los archivos del directorio reemplazan a los que tienen el mismo nombre
+	if len(overrides) > 0 {
+		sets[DefaultLocale], err = sets[DefaultLocale].ParseFiles(overrides...)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		locale := Normalize(entry.Name())
+
+		files, err := filepath.Glob(filepath.Join(dir, entry.Name(), "*.tmpl"))
+		if err != nil {
+			return err
+		}
+
+		if len(files) == 0 {
+			continue
+		}
+
+		set, exist := sets[locale]
+		if !exist {
+			set, err = sets[DefaultLocale].Clone()
+			if err != nil {
+				return err
+			}
+		}
+
+		sets[locale], err = set.ParseFiles(files...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Render devuelve el mensaje name en el idioma por defecto
+func (t *Templates) Render(name string, data interface{}) string {
+	return t.RenderIn(DefaultLocale, name, data)
+}
+
+// RenderIn devuelve el mensaje name en el idioma indicado, vacio si el template no existe o falla.
+// Si el idioma no tiene catalogo se usa el idioma por defecto.
+func (t *Templates) RenderIn(locale string, name string, data interface{}) string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	set, exist := t.sets[Normalize(locale)]
+	if !exist {
+		set = t.sets[DefaultLocale]
+	}
+
+	var buffer bytes.Buffer
+
+	err := set.ExecuteTemplate(&buffer, name, data)
+	if err != nil {
+		log.Printf("no se pudo generar el mensaje %s en %s: %v", name, locale, err)
+		return ""
+	}
+
+	return buffer.String()
+}
+
+// Supported indica si hay un catalogo para el idioma
+func (t *Templates) Supported(locale string) bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	_, exist := t.sets[Normalize(locale)]
+	return exist
+}
+
+// Locales devuelve los idiomas disponibles ordenados
+func (t *Templates) Locales() []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	locales := make([]string, 0, len(t.sets))
+	for locale := range t.sets {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// Normalize reduce un codigo de idioma de Telegram como es-419 o pt-BR a su idioma base
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+
+	return code
+}
@@ -0,0 +1,212 @@
+package notificator
+
+import (
+	"log"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+	"github.com/polarysfoundation/kilocompbot/bot/sender"
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+const (
+	// boardThrottle es el tiempo minimo entre dos ediciones del leaderboard de un grupo
+	boardThrottle = 15
+
+	// Modos de los anuncios de compra
+	AlertsFull    = "full"
+	AlertsCompact = "compact"
+	AlertsOff     = "off"
+)
+
+// liveBoards registra que leaderboards tienen cambios pendientes y cuando se editaron por ultima vez
+type liveBoards struct {
+	dirty  map[string]bool
+	edited map[string]int64
+	mutex  sync.Mutex
+}
+
+func initLiveBoards() *liveBoards {
+	return &liveBoards{
+		dirty:  make(map[string]bool),
+		edited: make(map[string]int64),
+	}
+}
+
+func ValidAlerts(mode string) bool {
+	switch mode {
+	case AlertsFull, AlertsCompact, AlertsOff:
+		return true
+	default:
+		return false
+	}
+}
+
+// PostBoard publica y fija el leaderboard del grupo si tiene activado el modo en vivo. El envio se espera en otra goroutine
+// para no frenar los comandos ni el scheduler detras de la cola del chat.
+func (g *Groups) PostBoard(chatID string) {
+	group, err := g.Groups.GetDataGroup(chatID)
+	if err != nil || !group.LiveBoard {
+		return
+	}
+
+	chatIDInt, _ := strconv.ParseInt(chatID, 10, 64)
+
+	msg := tgbotapi.NewMessage(chatIDInt, g.boardMessage(chatID, false))
+	msg.ParseMode = messages.ParseMode
+	msg.DisableWebPagePreview = true
+
+	go g.pinBoard(chatID, chatIDInt, msg)
+}
+
+func (g *Groups) pinBoard(chatID string, chatIDInt int64, msg tgbotapi.MessageConfig) {
+	sent, err := g.Sender.SendWait(chatIDInt, msg)
+	if err != nil {
+		log.Printf("no se pudo publicar el leaderboard del grupo %s: %v", chatID, err)
+		return
+	}
+
+	// La competencia pudo terminar o el modo en vivo desactivarse mientras el mensaje esperaba en la cola
+	group, err := g.Groups.GetDataGroup(chatID)
+	if err != nil || !group.LiveBoard || !g.Groups.CompStatus(chatID) {
+		return
+	}
+
+	err = g.Groups.SetBoardMessage(chatID, sent.MessageID)
+	if err != nil {
+		return
+	}
+	g.saveGroup(chatID)
+
+	_, err = g.BotAPI.PinChatMessage(tgbotapi.PinChatMessageConfig{
+		ChatID:              chatIDInt,
+		MessageID:           sent.MessageID,
+		DisableNotification: true,
+	})
+	if err != nil {
+		log.Printf("no se pudo fijar el leaderboard del grupo %s: %v", chatID, err)
+	}
+
+	g.boards.mutex.Lock()
+	g.boards.edited[chatID] = time.Now().Unix()
+	delete(g.boards.dirty, chatID)
+	g.boards.mutex.Unlock()
+}
+
+// MarkBoard indica que el ranking del grupo cambio y su leaderboard debe editarse
+func (g *Groups) MarkBoard(chatID string) {
+	g.boards.mutex.Lock()
+	defer g.boards.mutex.Unlock()
+
+	g.boards.dirty[chatID] = true
+}
+
+// CloseBoard deja el leaderboard con el resultado final y lo desfija
+func (g *Groups) CloseBoard(chatID string) {
+	group, err := g.Groups.GetDataGroup(chatID)
+	if err != nil || group.BoardMessageID == 0 {
+		return
+	}
+
+	chatIDInt, _ := strconv.ParseInt(chatID, 10, 64)
+
+	g.editBoard(chatIDInt, group.BoardMessageID, g.boardMessage(chatID, true), sender.PriorityHigh)
+
+	err = g.unpinMessage(chatIDInt, group.BoardMessageID)
+	if err != nil {
+		log.Printf("no se pudo desfijar el leaderboard del grupo %s: %v", chatID, err)
+	}
+
+	err = g.Groups.SetBoardMessage(chatID, 0)
+	if err != nil {
+		return
+	}
+
+	g.boards.mutex.Lock()
+	delete(g.boards.dirty, chatID)
+	delete(g.boards.edited, chatID)
+	g.boards.mutex.Unlock()
+}
+
+// unpinMessage desfija solo el mensaje indicado, UnpinChatMessageConfig no envia message_id y desfijaria
+// el ultimo mensaje fijado del chat aunque lo haya fijado un administrador del grupo
+func (g *Groups) unpinMessage(chatID int64, messageID int) error {
+	params := url.Values{}
+	params.Add("chat_id", strconv.FormatInt(chatID, 10))
+	params.Add("message_id", strconv.Itoa(messageID))
+
+	_, err := g.BotAPI.MakeRequest("unpinChatMessage", params)
+	return err
+}
+
+// refreshBoards edita los leaderboards con cambios pendientes respetando boardThrottle
+func (g *Groups) refreshBoards() {
+	now := time.Now().Unix()
+
+	for _, chatID := range g.Groups.IDs() {
+		group, err := g.Groups.GetDataGroup(chatID)
+		if err != nil {
+			continue
+		}
+
+		if !group.LiveBoard || group.BoardMessageID == 0 || !group.CompActive {
+			continue
+		}
+
+		g.boards.mutex.Lock()
+		pending := g.boards.dirty[chatID] && now-g.boards.edited[chatID] >= boardThrottle
+		if pending {
+			delete(g.boards.dirty, chatID)
+			g.boards.edited[chatID] = now
+		}
+		g.boards.mutex.Unlock()
+
+		if !pending {
+			continue
+		}
+
+		chatIDInt, _ := strconv.ParseInt(chatID, 10, 64)
+		g.editBoard(chatIDInt, group.BoardMessageID, g.boardMessage(chatID, false), sender.PriorityNormal)
+	}
+}
+
+func (g *Groups) editBoard(chatID int64, messageID int, text string, priority sender.Priority) {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = messages.ParseMode
+	edit.DisableWebPagePreview = true
+
+	if priority == sender.PriorityHigh {
+		g.Sender.SendPriority(chatID, edit)
+		return
+	}
+
+	g.Sender.Send(chatID, edit)
+}
+
+// liveBoard son los datos del leaderboard fijado
+type liveBoard struct {
+	Final     bool
+	Standings []*core.Purchase
+	TimeLeft  time.Duration
+	Paused    bool
+	Updated   string
+}
+
+func (g *Groups) boardMessage(chatID string, final bool) string {
+	board := &liveBoard{
+		Final:    final,
+		TimeLeft: g.comps.TimeLeft(chatID),
+		Paused:   g.comps.IsPaused(chatID),
+		Updated:  time.Now().UTC().Format("15:04 UTC"),
+	}
+
+	if purchases, err := g.comps.GetComp(chatID); err == nil {
+		board.Standings = purchases.GetCompList()
+	}
+
+	return g.render(chatID, "live_board", board)
+}
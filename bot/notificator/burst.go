@@ -0,0 +1,155 @@
+package notificator
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/groups"
+)
+
+// burstListed limita las compras listadas en el resumen, el caption de un video no admite mas de 1024 caracteres
+const burstListed = 8
+
+type pendingBuy struct {
+	Purchase *core.Purchase
+	Unranked string
+}
+
+// bursts agrupa las compras de los grupos que superan el limite de anuncios por ventana
+type bursts struct {
+	recent  map[string][]int64
+	pending map[string][]*pendingBuy
+	started map[string]int64
+	mutex   sync.Mutex
+}
+
+func initBursts() *bursts {
+	return &bursts{
+		recent:  make(map[string][]int64),
+		pending: make(map[string][]*pendingBuy),
+		started: make(map[string]int64),
+	}
+}
+
+// batchBuy registra la compra y devuelve true si el grupo esta en rafaga y la compra ira en el proximo resumen
+func (g *Groups) batchBuy(chatID string, group *groups.GroupData, order *core.Purchase, unranked string) bool {
+	if group.BurstThreshold <= 0 || group.BurstWindow <= 0 {
+		return false
+	}
+
+	g.bursts.mutex.Lock()
+	defer g.bursts.mutex.Unlock()
+
+	now := time.Now().Unix()
+
+	recent := make([]int64, 0, len(g.bursts.recent[chatID])+1)
+	for _, timestamp := range g.bursts.recent[chatID] {
+		if now-timestamp < group.BurstWindow {
+			recent = append(recent, timestamp)
+		}
+	}
+	recent = append(recent, now)
+	g.bursts.recent[chatID] = recent
+
+	if int64(len(recent)) <= group.BurstThreshold && len(g.bursts.pending[chatID]) == 0 {
+		return false
+	}
+
+	if len(g.bursts.pending[chatID]) == 0 {
+		g.bursts.started[chatID] = now
+	}
+
+	g.bursts.pending[chatID] = append(g.bursts.pending[chatID], &pendingBuy{Purchase: order, Unranked: unranked})
+
+	return true
+}
+
+// flushBursts envia el resumen de los grupos cuya rafaga lleva abierta al menos una ventana
+func (g *Groups) flushBursts() {
+	now := time.Now().Unix()
+
+	for _, chatID := range g.Groups.IDs() {
+		group, err := g.Groups.GetDataGroup(chatID)
+		if err != nil {
+			continue
+		}
+
+		g.bursts.mutex.Lock()
+		ready := len(g.bursts.pending[chatID]) > 0 && now-g.bursts.started[chatID] >= group.BurstWindow
+		g.bursts.mutex.Unlock()
+
+		if ready {
+			g.flushBurst(chatID)
+		}
+	}
+}
+
+// flushBurst envia el resumen pendiente del grupo sin esperar a que cierre la ventana
+func (g *Groups) flushBurst(chatID string) {
+	g.bursts.mutex.Lock()
+	pending := g.bursts.pending[chatID]
+	delete(g.bursts.pending, chatID)
+	delete(g.bursts.started, chatID)
+	g.bursts.mutex.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	chatIDInt, _ := strconv.ParseInt(chatID, 10, 64)
+
+	group, err := g.Groups.GetDataGroup(chatID)
+	if err != nil {
+		return
+	}
+
+	if group.BuyAlerts == AlertsCompact {
+		g.send(chatIDInt, g.burstMessage(chatID, pending, false, ""))
+		return
+	}
+
+	log.Printf("enviando resumen de %d compras al grupo %s", len(pending), chatID)
+
+	ad := g.promotions.Pick(chatID, time.Now())
+	markup := keyboardMarkup(ad.ButtonName, g.tracker.Link(ad, chatID))
+	g.newNotification(g.burstMessage(chatID, pending, true, ad.AdName), chatIDInt, group, ad, markup)
+}
+
+// burstSummary son los datos del resumen de una rafaga de compras
+type burstSummary struct {
+	Name     string
+	Count    int
+	Buys     []*pendingBuy
+	More     int
+	Full     bool
+	Leaders  []*core.Purchase
+	TimeLeft time.Duration
+	Paused   bool
+	Ad       string
+}
+
+func (g *Groups) burstMessage(chatID string, pending []*pendingBuy, full bool, ad string) string {
+	summary := &burstSummary{
+		Name:     pending[0].Purchase.JettonName,
+		Count:    len(pending),
+		Buys:     pending,
+		Full:     full,
+		TimeLeft: g.comps.TimeLeft(chatID),
+		Paused:   g.comps.IsPaused(chatID),
+		Ad:       ad,
+	}
+
+	if len(pending) > burstListed {
+		summary.Buys = pending[:burstListed]
+		summary.More = len(pending) - burstListed
+	}
+
+	if purchases, err := g.comps.GetComp(chatID); err == nil {
+		summary.Leaders = purchases.GetCompList()
+	}
+
+	return g.render(chatID, "burst_summary", summary)
+}
@@ -0,0 +1,167 @@
+package notificator
+
+import (
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/groups"
+)
+
+// sendMedia envia el anuncio con el metodo que corresponde al tipo de media. Se usa la media propia del grupo si tiene,
+// despues la de la campaña del anuncio, y la media global se reenvia por su file_id y solo se sube desde el archivo local
+// cuando no hay un file_id valido. Devuelve el error del envio, nil si el anuncio llego al grupo.
+func (g *Groups) sendMedia(text string, chatID int64, group *groups.GroupData, ad promotions.Ad, markup *tgbotapi.InlineKeyboardMarkup) error {
+	if group != nil {
+		fileID, mediaType := g.Groups.AlertMedia(group.ID)
+		if mediaType != "" {
+			if handled, err := g.sendGroupMedia(text, chatID, group.ID, fileID, mediaType, markup); handled {
+				return err
+			}
+		}
+	}
+
+	if ad.MediaType != "" {
+		if handled, err := g.sendCampaignMedia(text, chatID, ad, markup); handled {
+			return err
+		}
+	}
+
+	media, fileID, mediaType := g.promotions.GetMedia()
+
+	if mediaType == promotions.MediaNone {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = messages.ParseMode
+		msg.ReplyMarkup = markup
+		_, err := g.Sender.SendWait(chatID, msg)
+		return err
+	}
+
+	if fileID != "" {
+		_, err := g.Sender.SendWait(chatID, mediaMessage(chatID, mediaType, fileID, false, text, markup))
+		if err == nil || !invalidFileID(err) {
+			return err
+		}
+
+		log.Printf("el file_id de la media %s ya no es valido, se subira de nuevo: %v", media, err)
+		g.promotions.ClearMediaFileID(fileID)
+	}
+
+	sent, err := g.Sender.SendWait(chatID, mediaMessage(chatID, mediaType, media, true, text, markup))
+	if err != nil {
+		return err
+	}
+
+	if uploaded := sentFileID(sent); uploaded != "" {
+		g.promotions.CacheMediaFileID(media, uploaded)
+	}
+
+	return nil
+}
+
+// mediaMessage arma el mensaje del tipo de media indicado, con upload file es la ruta de un archivo local y si no un file_id
+func mediaMessage(chatID int64, mediaType string, file string, upload bool, text string, markup *tgbotapi.InlineKeyboardMarkup) tgbotapi.Chattable {
+	share := !upload
+
+	switch mediaType {
+	case promotions.MediaPhoto:
+		msg := tgbotapi.NewPhotoUpload(chatID, file)
+		if share {
+			msg = tgbotapi.NewPhotoShare(chatID, file)
+		}
+		msg.Caption = text
+		msg.ParseMode = messages.ParseMode
+		msg.ReplyMarkup = markup
+		return msg
+	case promotions.MediaAnimation:
+		msg := tgbotapi.NewAnimationUpload(chatID, file)
+		if share {
+			msg = tgbotapi.NewAnimationShare(chatID, file)
+		}
+		msg.Caption = text
+		msg.ParseMode = messages.ParseMode
+		msg.ReplyMarkup = markup
+		return msg
+	default:
+		msg := tgbotapi.NewVideoUpload(chatID, file)
+		if share {
+			msg = tgbotapi.NewVideoShare(chatID, file)
+		}
+		msg.Caption = text
+		msg.ParseMode = messages.ParseMode
+		msg.ReplyMarkup = markup
+		return msg
+	}
+}
+
+// sendGroupMedia envia el anuncio con la media del grupo, devuelve false si hay que usar la media global
+func (g *Groups) sendGroupMedia(text string, chatID int64, groupID string, fileID string, mediaType string, markup *tgbotapi.InlineKeyboardMarkup) (bool, error) {
+	if mediaType == promotions.MediaNone {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = messages.ParseMode
+		msg.ReplyMarkup = markup
+		_, err := g.Sender.SendWait(chatID, msg)
+		return true, err
+	}
+
+	_, err := g.Sender.SendWait(chatID, mediaMessage(chatID, mediaType, fileID, false, text, markup))
+	if err == nil || !invalidFileID(err) {
+		return true, err
+	}
+
+	// Los grupos solo guardan el file_id, si Telegram ya no lo acepta se vuelve a la media global
+	log.Printf("el file_id de la media del grupo %s ya no es valido: %v", groupID, err)
+	if g.Groups.ClearAlertMedia(groupID, fileID) {
+		g.saveGroup(groupID)
+	}
+
+	return false, nil
+}
+
+// sendCampaignMedia envia el anuncio con la media de la campaña, devuelve false si hay que usar la media global
+func (g *Groups) sendCampaignMedia(text string, chatID int64, ad promotions.Ad, markup *tgbotapi.InlineKeyboardMarkup) (bool, error) {
+	if ad.MediaType == promotions.MediaNone {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = messages.ParseMode
+		msg.ReplyMarkup = markup
+		_, err := g.Sender.SendWait(chatID, msg)
+		return true, err
+	}
+
+	_, err := g.Sender.SendWait(chatID, mediaMessage(chatID, ad.MediaType, ad.MediaFileID, false, text, markup))
+	if err == nil || !invalidFileID(err) {
+		return true, err
+	}
+
+	log.Printf("el file_id de la media de la campaña %d ya no es valido: %v", ad.CampaignID, err)
+	g.promotions.ClearCampaignMedia(ad.CampaignID, ad.MediaFileID)
+
+	return false, nil
+}
+
+func sentFileID(sent tgbotapi.Message) string {
+	switch {
+	case sent.Video != nil:
+		return sent.Video.FileID
+	case sent.Animation != nil:
+		return sent.Animation.FileID
+	case sent.Photo != nil && len(*sent.Photo) > 0:
+		photos := *sent.Photo
+		return photos[len(photos)-1].FileID
+	default:
+		return ""
+	}
+}
+
+func invalidFileID(err error) bool {
+	apiErr, ok := err.(tgbotapi.Error)
+	if !ok {
+		return false
+	}
+
+	message := strings.ToLower(apiErr.Message)
+
+	return strings.Contains(message, "file identifier") || strings.Contains(message, "file_id") || strings.Contains(message, "wrong type of the web page content")
+}
@@ -3,16 +3,16 @@ package notificator
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"log"
 	"math/rand"
-	"net/url"
 	"strconv"
 	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
 	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/bot/sender"
 	"github.com/polarysfoundation/kilocompbot/core"
 	"github.com/polarysfoundation/kilocompbot/database"
 	"github.com/polarysfoundation/kilocompbot/groups"
@@ -26,10 +26,6 @@ var (
 	errEventNotExist     = errors.New("error: instancia de evento no existe")
 )
 
-const (
-	compEnded = "The competition is over."
-)
-
 type Events struct {
 	events map[string]*indexer.Events
 	mutex  sync.RWMutex
@@ -81,27 +77,44 @@ type Groups struct {
 	Groups *groups.Groups
 	DB     *sql.DB
 	BotAPI *tgbotapi.BotAPI
+	Sender *sender.Queue
+	// Messages contiene los textos de todos los mensajes
+	Messages *messages.Templates
 
-	events *Events
-	comps  *core.Competition
-	api    string
+	events     *Events
+	comps      *core.Competition
+	exclusions *core.Exclusions
+	api        string
 
 	promotions *promotions.Params
+	tracker    *promotions.Tracker
+
+	// reminded guarda el ultimo aviso de tiempo restante enviado a cada grupo
+	reminded map[string]time.Duration
+	boards   *liveBoards
+	bursts   *bursts
 
 	mutex sync.RWMutex
 }
 
-func Init(bot *tgbotapi.BotAPI, groups *groups.Groups, comps *core.Competition, events *Events, api string, params *promotions.Params, db *sql.DB) *Groups {
+func Init(bot *tgbotapi.BotAPI, queue *sender.Queue, texts *messages.Templates, groups *groups.Groups, comps *core.Competition, events *Events, api string, params *promotions.Params, tracker *promotions.Tracker, db *sql.DB, exclusions *core.Exclusions) *Groups {
 	return &Groups{
 		ID:         make([]string, 0),
 		Ticker:     make(map[string]*time.Ticker),
 		Groups:     groups,
 		DB:         db,
 		BotAPI:     bot,
+		Sender:     queue,
+		Messages:   texts,
 		events:     events,
 		comps:      comps,
+		exclusions: exclusions,
 		api:        api,
 		promotions: params,
+		tracker:    tracker,
+		reminded:   make(map[string]time.Duration),
+		boards:     initLiveBoards(),
+		bursts:     initBursts(),
 	}
 }
 
@@ -142,6 +155,11 @@ func (g *Groups) HandleUpdate() {
 		for {
 			time.Sleep(5 * time.Second)
 
+			g.startScheduled()
+			g.sendReminders()
+			g.refreshBoards()
+			g.flushBursts()
+
 			tickerMutex.Lock()
 
 			// Iniciar tickers para nuevos grupos
@@ -203,7 +221,11 @@ func (g *Groups) handleEvent(chatID string) {
 	chatIDInt, _ := strconv.Atoi(chatID)
 
 	pools := make([]string, 0)
-	group := g.Groups.ActiveGroups[chatID]
+	group, err := g.Groups.GetDataGroup(chatID)
+	if err != nil {
+		log.Printf("no se encontro el grupo %s: %v", chatID, err)
+		return
+	}
 
 	if group.Dedust != "" {
 		pools = append(pools, group.Dedust)
@@ -239,25 +261,22 @@ func (g *Groups) handleEvent(chatID string) {
 			/* continue */
 		}
 
-		_, err = database.RemoveCompData(g.DB, chatID)
-		if err != nil {
-			log.Printf("no se pudo remover la competencia para el grupo %s", chatID)
-			/* continue */
-		}
+		g.RemoveCompetitionData(chatID)
 
-		_, err = database.RemoveEndTimeData(g.DB, chatID)
-		if err != nil {
-			log.Printf("no se pudo remover el tiempo para el grupo %s", chatID)
-			/* continue */
-		}
+		g.flushBurst(chatID)
+		g.CloseBoard(chatID)
 
-		_, err = database.RemoveSaleData(g.DB, chatID)
+		err = g.comps.RemoveBlacklistActive(chatID)
 		if err != nil {
-			log.Printf("no se pudo remover la venta para el grupo %s", chatID)
-			/* continue */
+			log.Printf("no se pudo eliminar la blacklist para el grupo %s", chatID)
 		}
 
-		g.send(int64(chatIDInt), compEnded)
+		g.sendPriority(int64(chatIDInt), g.render(chatID, "comp_ended", nil))
+		return
+	}
+
+	// La competencia de un grupo deshabilitado termina igual, pero no se registran ni anuncian compras
+	if group.Disabled {
 		return
 	}
 
@@ -285,41 +304,26 @@ func (g *Groups) handleEvent(chatID string) {
 				return
 			}
 
-			err = blacklist.AddSale(tx)
+			sale, err := blacklist.AddSale(tx)
 			if err != nil {
-				log.Printf("error mientras se creaba una nueva venta")
+				log.Printf("error mientras se creaba una nueva venta: %v", err)
 				return
 			}
 
-			if g.comps.CompExist(chatID) {
-				buy, err := g.comps.GetComp(chatID)
-				if err != nil {
-					log.Printf("no se pudo obtener la blacklist del grupo %s", chatID)
-					return
-				}
-
-				hash, err := blacklist.GetSaleHash(tx)
-				if err != nil {
-					log.Print("error obteniendo el hash de la venta")
-					return
-				}
+			policy := core.SellPolicy{
+				Mode:      group.SellPolicy,
+				Tolerance: group.SellTolerance,
+			}
 
-				sale, err := blacklist.GetSale(hash)
-				if err != nil {
-					log.Printf("error obteniendo la venta con el hash %s ", hash)
-					return
-				}
+			outcome, err := g.comps.ApplySellPolicy(chatID, sale, policy)
+			if err != nil {
+				log.Printf("no se pudo aplicar la politica de venta para el grupo %s: %v", chatID, err)
+				return
+			}
 
-				for buyHash, purchase := range buy.Purchase {
-					if sale.Seller == purchase.Buyer {
-						err := buy.RemovePurchase(buyHash)
-						if err != nil {
-							log.Printf("no se pudo remover la compra con hash: %s", hash)
-							return
-						}
-						break
-					}
-				}
+			if outcome.Competitor {
+				g.MarkBoard(chatID)
+				g.send(int64(chatIDInt), g.render(chatID, "sell_alert", &sellAlert{Sale: sale, Outcome: outcome}))
 			}
 		}
 
@@ -338,13 +342,28 @@ func (g *Groups) handleEvent(chatID string) {
 				return
 			}
 
-			order, err := buy.AddPurchase(tx)
-			if err != nil {
-				log.Printf("error mientras se creaba una nueva compra")
+			limits := core.Limits{
+				MinBuy:     group.MinBuy,
+				MaxBuy:     group.MaxBuy,
+				Exclusions: g.exclusions,
+				GroupID:    chatID,
+				Paused:     g.comps.IsPaused(chatID),
+			}
+
+			unranked := ""
+			if limits.Paused {
+				unranked = g.render(chatID, "not_ranked_paused", nil)
+			}
+
+			order, err := buy.AddPurchase(tx, limits)
+			if err == core.ErrExcludedBuyer && group.AnnounceExcluded {
+				unranked = g.render(chatID, "not_ranked_excluded", nil)
+			} else if err != nil {
+				log.Printf("error mientras se creaba una nueva compra: %v", err)
 				return
 			}
 
-			if g.comps.BlackListExist(chatID) {
+			if err == nil && g.comps.BlackListExist(chatID) {
 				blacklist, err := g.comps.GetBlacklist(chatID)
 				if err != nil {
 					log.Printf("no se pudo obtener la blacklist del grupo %s", chatID)
@@ -357,45 +376,76 @@ func (g *Groups) handleEvent(chatID string) {
 					return
 				}
 
-				for _, sale := range blacklist.Sale {
-					if order.Buyer == sale.Seller {
-						err := buy.RemovePurchase(hash)
-						if err != nil {
-							log.Printf("no se pudo remover la compra con hash: %s", hash)
-							return
-						}
-						break
+				if blacklist.IsDisqualified(order.Buyer) {
+					err := buy.RemovePurchase(hash)
+					if err != nil {
+						log.Printf("no se pudo remover la compra con hash: %s", hash)
+						return
 					}
 				}
 			}
 
+			if unranked == "" {
+				g.MarkBoard(chatID)
+			}
+
+			if group.BuyAlerts == AlertsOff {
+				continue
+			}
+
+			if g.batchBuy(chatID, group, order, unranked) {
+				continue
+			}
+
+			if group.BuyAlerts == AlertsCompact {
+				g.send(int64(chatIDInt), g.render(chatID, "compact_buy", &pendingBuy{Purchase: order, Unranked: unranked}))
+				continue
+			}
+
 			compList := buy.GetCompList()
 
-			msg := g.generateMessage(order, chatID, compList)
-			keyboardMarkup := keyboardMarkup(g.promotions.ButtonName, g.promotions.ButtonLink)
-			g.newNotification(msg, int64(chatIDInt), g.promotions.Media, keyboardMarkup)
+			ad := g.promotions.Pick(chatID, time.Now())
+			msg := g.generateMessage(order, chatID, compList, unranked, ad)
+			keyboardMarkup := keyboardMarkup(ad.ButtonName, g.tracker.Link(ad, chatID))
+			g.newNotification(msg, int64(chatIDInt), group, ad, keyboardMarkup)
 		}
 	}
 
 }
 
-func (g *Groups) newNotification(text string, chatID int64, media string, markup *tgbotapi.InlineKeyboardMarkup) {
-	video := media
-	msg := tgbotapi.NewVideoUpload(chatID, video)
-	msg.Caption = text
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = markup
-	_, err := g.BotAPI.Send(msg)
-	if err != nil {
-		log.Println("Error al enviar mensaje:", err)
-	}
+// newNotification envia el anuncio con la media del grupo, la de la campaña o la de la promo, se espera el resultado en otra goroutine
+// para cachear el file_id y contar la impresion solo si el anuncio llego al grupo
+func (g *Groups) newNotification(text string, chatID int64, group *groups.GroupData, ad promotions.Ad, markup *tgbotapi.InlineKeyboardMarkup) {
+	go func() {
+		err := g.sendMedia(text, chatID, group, ad, markup)
+		if err != nil {
+			return
+		}
+
+		g.tracker.Impression(ad.CampaignID, strconv.FormatInt(chatID, 10))
+	}()
 }
 
-func (g *Groups) generateMessage(tx *core.Purchase, id string, compList []*core.Purchase) string {
-	header := fmt.Sprintf("🚨*%s New Buy*🚨\n\n", tx.JettonName)
+// buyAlert son los datos del anuncio de una compra
+type buyAlert struct {
+	Purchase *core.Purchase
+	Emojis   string
+	Capped   bool
+	Spot     string
+	Leaders  []*core.Purchase
+	TimeLeft time.Duration
+	Paused   bool
+	Ad       string
+}
 
-	emojis := g.calcularCantidadEmoji(int(tx.Ton.Int64()), id)
+// sellAlert son los datos del anuncio de una venta
+type sellAlert struct {
+	Sale    *core.Sale
+	Outcome *core.SellOutcome
+}
 
+// generateMessage arma el anuncio de la compra, unranked explica por que la compra no entra al ranking
+func (g *Groups) generateMessage(tx *core.Purchase, id string, compList []*core.Purchase, unranked string, ad promotions.Ad) string {
 	buyerIndex := 0
 
 	for i, comp := range compList {
@@ -404,38 +454,39 @@ func (g *Groups) generateMessage(tx *core.Purchase, id string, compList []*core.
 		}
 	}
 
-	spent := fmt.Sprintf("\n\n💰Spent: %d *TON*\n", tx.Ton)
-	got := fmt.Sprintf("🧳Bought: %s *%s*\n", formatWithCommas(tx.Token.Int64()), tx.JettonSymbol)
-	compspot := fmt.Sprintf("📊Competition Spot: %d\n", buyerIndex+1)
+	spot := strconv.Itoa(buyerIndex + 1)
 
 	if buyerIndex == 0 {
-		compspot = fmt.Sprintf("📊Competition Spot: %s\n", "New competitor")
+		spot = g.render(id, "new_competitor", nil)
 	}
 
-	walletEnc := url.QueryEscape(tx.Buyer)
-	lenWallet := len(tx.Buyer)
-	wallet := fmt.Sprintf("💎Wallet: [%s...%s](https://tonviewer.com/%s/)\n", tx.Buyer[:6], tx.Buyer[lenWallet-6:], walletEnc)
-
-	leadingBoard := "\n*Leading Buys:*\n"
-
-	var place1, place2, place3 string
-	if len(compList) > 0 {
-		place1 = fmt.Sprintf("🥇%d *TON*  -  [%s...%s](https://tonviewer.com/%s/)\n", compList[0].Ton, compList[0].Buyer[:6], compList[0].Buyer[lenWallet-6:], url.QueryEscape(compList[0].Buyer))
-	}
-	if len(compList) > 1 {
-		place2 = fmt.Sprintf("🥈%d *TON*  -  [%s...%s](https://tonviewer.com/%s/)\n", compList[1].Ton, compList[1].Buyer[:6], compList[1].Buyer[lenWallet-6:], url.QueryEscape(compList[1].Buyer))
+	if unranked != "" {
+		spot = unranked
 	}
-	if len(compList) > 2 {
-		place3 = fmt.Sprintf("🥉%d *TON*  -  [%s...%s](https://tonviewer.com/%s/)\n", compList[2].Ton, compList[2].Buyer[:6], compList[2].Buyer[lenWallet-6:], url.QueryEscape(compList[2].Buyer))
-	}
-
-	endIn := timeUntilEnd(g.comps.Timestamp[id])
 
-	foot := fmt.Sprintf("Buy competition end at %s", endIn)
+	alert := &buyAlert{
+		Purchase: tx,
+		Emojis:   g.calcularCantidadEmoji(int(tx.Ton.Int64()), id),
+		Capped:   tx.Score.Cmp(tx.Ton) < 0,
+		Spot:     spot,
+		Leaders:  compList,
+		TimeLeft: g.comps.TimeLeft(id),
+		Paused:   g.comps.IsPaused(id),
+		Ad:       ad.AdName,
+	}
 
-	concatened := header + emojis + spent + got + compspot + wallet + leadingBoard + place1 + place2 + place3 + foot + "\n" + "\n" + g.promotions.AdName + "\n" + "\n"
+	if group, err := g.Groups.GetDataGroup(id); err == nil && group.AlertTemplate != "" {
+		values := &alertValues{
+			purchase:    tx,
+			rank:        spot,
+			endsIn:      g.render(id, "time_left", alert),
+			emojis:      alert.Emojis,
+			leaderboard: g.render(id, "leading_buys", compList),
+		}
+		return renderAlert(group.AlertTemplate, values) + "\n" + "\n" + messages.Escape(ad.AdName) + "\n" + "\n"
+	}
 
-	return concatened
+	return g.render(id, "buy_alert", alert)
 }
 
 func (g *Groups) calcularCantidadEmoji(amount int, id string) string {
@@ -478,49 +529,73 @@ func repeatEmoji(emoji string, times int) string {
 	return result
 }
 
-func formatWithCommas(num int64) string {
-	// Convertir el int64 a una cadena sin comas
-	str := strconv.FormatInt(num, 10)
+func keyboardMarkup(buttonContext string, buttonContent string) *tgbotapi.InlineKeyboardMarkup {
+	urlButton := tgbotapi.NewInlineKeyboardButtonURL(buttonContext, buttonContent)
+	row := []tgbotapi.InlineKeyboardButton{urlButton}
+	inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return &inlineKeyboard
+}
 
-	// Calcular la longitud de la cadena
-	n := len(str)
+func (p *Groups) send(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = messages.ParseMode
+	p.Sender.Send(chatID, msg)
+}
 
-	// Construir la cadena con comas
-	var formatted string
-	for i, char := range str {
-		// Insertar una coma si la posición es divisible por 3 y no es el primer dígito
-		if i > 0 && (n-i)%3 == 0 {
-			formatted += ","
-		}
-		formatted += string(char)
+// sendPriority envia un mensaje que debe salir antes que los anuncios pendientes del grupo
+func (p *Groups) sendPriority(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = messages.ParseMode
+	p.Sender.SendPriority(chatID, msg)
+}
+
+// render genera el mensaje name en el idioma del grupo
+func (p *Groups) render(chatID string, name string, data interface{}) string {
+	locale := messages.DefaultLocale
+	if group, err := p.Groups.GetDataGroup(chatID); err == nil && group.Locale != "" {
+		locale = group.Locale
 	}
 
-	return formatted
+	return p.Messages.RenderIn(locale, name, data)
 }
 
-func timeUntilEnd(timestamp int64) string {
-	endTime := time.Unix(timestamp, 0)
-	duration := time.Until(endTime)
+// saveGroup guarda la configuracion del grupo de inmediato, sin esperar al siguiente backup
+func (g *Groups) saveGroup(chatID string) {
+	if g.DB == nil {
+		return
+	}
 
-	hours := int(duration.Hours())
-	minutes := int(duration.Minutes()) % 60
-	seconds := int(duration.Seconds()) % 60
+	group, err := g.Groups.GetDataGroup(chatID)
+	if err != nil {
+		return
+	}
 
-	return fmt.Sprintf("*%d hours: %d minutes: %d sec*", hours, minutes, seconds)
+	err = database.WriteGroups(g.DB, group)
+	if err != nil {
+		log.Printf("no se pudo guardar el grupo %s: %v", chatID, err)
+	}
 }
 
-func keyboardMarkup(buttonContext string, buttonContent string) *tgbotapi.InlineKeyboardMarkup {
-	urlButton := tgbotapi.NewInlineKeyboardButtonURL(buttonContext, buttonContent)
-	row := []tgbotapi.InlineKeyboardButton{urlButton}
-	inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(row)
-	return &inlineKeyboard
-}
+// RemoveCompetitionData borra de la base de datos las compras, ventas, descalificaciones y el tiempo de la competencia
+// terminada, asi un reinicio no las carga en la siguiente competencia del grupo
+func (g *Groups) RemoveCompetitionData(chatID string) {
+	_, err := database.RemoveCompData(g.DB, chatID)
+	if err != nil {
+		log.Printf("no se pudo remover la competencia para el grupo %s", chatID)
+	}
 
-func (p *Groups) send(chatID int64, text string) {
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
-	_, err := p.BotAPI.Send(msg)
+	_, err = database.RemoveEndTimeData(g.DB, chatID)
+	if err != nil {
+		log.Printf("no se pudo remover el tiempo para el grupo %s", chatID)
+	}
+
+	_, err = database.RemoveSaleData(g.DB, chatID)
+	if err != nil {
+		log.Printf("no se pudo remover la venta para el grupo %s", chatID)
+	}
+
+	_, err = database.RemoveDisqualifiedData(g.DB, chatID)
 	if err != nil {
-		log.Println("Error al enviar mensaje:", err)
+		log.Printf("no se pudo remover las wallets descalificadas para el grupo %s", chatID)
 	}
 }
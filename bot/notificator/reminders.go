@@ -0,0 +1,76 @@
+package notificator
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+const reminderStandings = 5
+
+// sendReminders publica el tiempo restante y el ranking cuando una competencia cruza uno de los avisos del grupo
+func (g *Groups) sendReminders() {
+	for _, chatID := range g.Groups.IDs() {
+		group, err := g.Groups.GetDataGroup(chatID)
+		if err != nil {
+			continue
+		}
+
+		if !group.CompActive || group.Disabled || !g.comps.TimestampExist(chatID) || g.comps.IsPaused(chatID) {
+			delete(g.reminded, chatID)
+			continue
+		}
+
+		reminders, err := core.ParseReminders(group.Reminders)
+		if err != nil || len(reminders) == 0 {
+			continue
+		}
+
+		left := g.comps.TimeLeft(chatID)
+		if left <= 0 {
+			continue
+		}
+
+		// crossed es el aviso mas chico que ya se alcanzo, 0 si todavia no se alcanza ninguno
+		var crossed time.Duration
+		for _, reminder := range reminders {
+			if left <= reminder {
+				crossed = reminder
+			}
+		}
+
+		last, seen := g.reminded[chatID]
+		g.reminded[chatID] = crossed
+
+		// La primera vez que se ve la competencia, o si se extendio, solo se registra el aviso sin enviarlo
+		if !seen || crossed == 0 || (last != 0 && crossed >= last) {
+			continue
+		}
+
+		chatIDInt, _ := strconv.ParseInt(chatID, 10, 64)
+		g.send(chatIDInt, g.reminderMessage(chatID, left))
+
+		log.Printf("aviso de %s enviado al grupo %s", core.FormatDuration(crossed), chatID)
+	}
+}
+
+// reminder son los datos del aviso de tiempo restante
+type reminder struct {
+	TimeLeft  time.Duration
+	Standings []*core.Purchase
+}
+
+func (g *Groups) reminderMessage(chatID string, left time.Duration) string {
+	data := &reminder{TimeLeft: left}
+
+	if purchases, err := g.comps.GetComp(chatID); err == nil {
+		data.Standings = purchases.GetCompList()
+		if len(data.Standings) > reminderStandings {
+			data.Standings = data.Standings[:reminderStandings]
+		}
+	}
+
+	return g.render(chatID, "reminder", data)
+}
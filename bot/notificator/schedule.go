@@ -0,0 +1,80 @@
+package notificator
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/polarysfoundation/kilocompbot/groups"
+)
+
+// StartCompetition limpia los datos de la competencia anterior y arranca una nueva que termina en endTime
+func (g *Groups) StartCompetition(chatID string, endTime int64) error {
+	if !g.Groups.GroupExist(chatID) {
+		return errIDNotExist
+	}
+
+	// Los errores solo indican que no quedaban datos de la competencia anterior
+	g.comps.RemoveCompActive(chatID)
+	g.comps.RemoveBlacklistActive(chatID)
+	g.comps.RemoveTimestampActive(chatID)
+
+	err := g.comps.NewTimestamp(chatID, endTime)
+	if err != nil {
+		return err
+	}
+
+	err = g.AddNewTicker(chatID)
+	if err != nil && err != errIDAlreadyExist {
+		return err
+	}
+
+	return g.Groups.UpdateCompStatus(chatID, true)
+}
+
+// CompetitionStarted son los datos del anuncio de inicio de una competencia
+type CompetitionStarted struct {
+	Group   *groups.GroupData
+	EndTime int64
+}
+
+// startScheduled arranca las competencias programadas con /schedulecomp cuya hora de inicio ya llego
+func (g *Groups) startScheduled() {
+	now := time.Now().Unix()
+
+	for _, chatID := range g.Groups.IDs() {
+		group, err := g.Groups.GetDataGroup(chatID)
+		if err != nil {
+			continue
+		}
+
+		if group.ScheduledStart == 0 || group.ScheduledStart > now || group.Disabled {
+			continue
+		}
+
+		// Si sigue activa la competencia anterior se espera a que termine
+		if group.CompActive || group.JettonAddress == "" {
+			continue
+		}
+
+		endTime := now + group.ScheduledDuration
+
+		err = g.StartCompetition(chatID, endTime)
+		if err != nil {
+			log.Printf("no se pudo iniciar la competencia programada para el grupo %s: %v", chatID, err)
+			continue
+		}
+
+		group.ScheduledStart = 0
+		group.ScheduledDuration = 0
+
+		// Se guarda de inmediato para que un reinicio antes del siguiente backup no vuelva a iniciarla
+		g.saveGroup(chatID)
+
+		log.Printf("competencia programada iniciada para el grupo %s", chatID)
+
+		chatIDInt, _ := strconv.ParseInt(chatID, 10, 64)
+		g.send(chatIDInt, g.render(chatID, "comp_started", &CompetitionStarted{Group: group, EndTime: endTime}))
+		g.PostBoard(chatID)
+	}
+}
@@ -0,0 +1,41 @@
+package notificator
+
+import (
+	"strings"
+
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
+	"github.com/polarysfoundation/kilocompbot/core"
+)
+
+// AlertPlaceholders son los campos que admite la plantilla de anuncio de un grupo
+const AlertPlaceholders = "{buyer}, {wallet}, {ton}, {counted}, {tokens}, {symbol}, {name}, {rank}, {ends_in}, {emojis}, {leaderboard}"
+
+type alertValues struct {
+	purchase    *core.Purchase
+	rank        string
+	endsIn      string
+	emojis      string
+	leaderboard string
+}
+
+// renderAlert reemplaza los campos de la plantilla del grupo con los datos de la compra.
+// La plantilla y los datos de la compra se escapan, rank, ends_in y leaderboard ya vienen generados en HTML.
+func renderAlert(template string, alert *alertValues) string {
+	tx := alert.purchase
+
+	replacer := strings.NewReplacer(
+		"{buyer}", messages.WalletLink(tx.Buyer),
+		"{wallet}", messages.Escape(tx.Buyer),
+		"{ton}", tx.Ton.String(),
+		"{counted}", tx.Score.String(),
+		"{tokens}", messages.Amount(tx.Token),
+		"{symbol}", messages.Escape(tx.JettonSymbol),
+		"{name}", messages.Escape(tx.JettonName),
+		"{rank}", alert.rank,
+		"{ends_in}", alert.endsIn,
+		"{emojis}", messages.Escape(alert.emojis),
+		"{leaderboard}", alert.leaderboard,
+	)
+
+	return replacer.Replace(messages.Escape(template))
+}
@@ -0,0 +1,219 @@
+package promotions
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+var (
+	errCampaignNotExist  = errors.New("error: la campaña no existe")
+	errInvalidCampaign   = errors.New("error: la campaña necesita texto, boton, link y un periodo valido")
+	errInvalidAdWeight   = errors.New("error: el peso de la campaña debe ser mayor a 0")
+	errCampaignIDInvalid = errors.New("error: id de campaña invalido")
+)
+
+// Campaign es un anuncio pagado que rota con los demas mientras esta activo. Sin Groups se muestra en todos los grupos.
+// La media se guarda solo como file_id de Telegram, sin media se usa la del anuncio por defecto.
+type Campaign struct {
+	ID          int64
+	AdName      string
+	ButtonName  string
+	ButtonLink  string
+	MediaFileID string
+	MediaType   string
+	Start       int64
+	End         int64
+	Weight      int64
+	Groups      []string
+}
+
+// Live indica si la campaña se puede mostrar en el grupo en el momento indicado
+func (c *Campaign) Live(groupID string, now int64) bool {
+	if now < c.Start || now >= c.End {
+		return false
+	}
+
+	if len(c.Groups) == 0 {
+		return true
+	}
+
+	for _, id := range c.Groups {
+		if id == groupID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ad es el anuncio que acompaña a un mensaje, CampaignID es 0 cuando es el anuncio por defecto
+type Ad struct {
+	CampaignID  int64
+	AdName      string
+	ButtonName  string
+	ButtonLink  string
+	MediaFileID string
+	MediaType   string
+}
+
+// AddCampaign registra la campaña y devuelve su id. Si la campaña ya trae id, como al cargarla de la base de datos, se conserva.
+func (p *Params) AddCampaign(campaign Campaign) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if campaign.AdName == "" || campaign.ButtonName == "" || campaign.ButtonLink == "" || campaign.End <= campaign.Start {
+		return 0, errInvalidCampaign
+	}
+
+	if campaign.Weight <= 0 {
+		return 0, errInvalidAdWeight
+	}
+
+	if campaign.MediaType != "" && !ValidMediaType(campaign.MediaType) {
+		return 0, errInvalidMediaType
+	}
+
+	if campaign.ID < 0 {
+		return 0, errCampaignIDInvalid
+	}
+
+	if p.campaigns == nil {
+		p.campaigns = make(map[int64]*Campaign)
+	}
+
+	if campaign.ID == 0 {
+		campaign.ID = p.lastCampaignID + 1
+	}
+
+	if campaign.ID > p.lastCampaignID {
+		p.lastCampaignID = campaign.ID
+	}
+
+	p.campaigns[campaign.ID] = &campaign
+
+	return campaign.ID, nil
+}
+
+// SeedCampaignID continua la numeracion de las campañas desde el ultimo id guardado, incluidas las campañas ya borradas
+func (p *Params) SeedCampaignID(id int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if id > p.lastCampaignID {
+		p.lastCampaignID = id
+	}
+}
+
+// LastCampaignID devuelve el ultimo id de campaña asignado
+func (p *Params) LastCampaignID() int64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.lastCampaignID
+}
+
+func (p *Params) RemoveCampaign(id int64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, exist := p.campaigns[id]; !exist {
+		return errCampaignNotExist
+	}
+
+	delete(p.campaigns, id)
+
+	return nil
+}
+
+// Campaigns devuelve una copia de las campañas ordenadas por id
+func (p *Params) Campaigns() []Campaign {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	campaigns := make([]Campaign, 0, len(p.campaigns))
+	for _, campaign := range p.campaigns {
+		campaigns = append(campaigns, *campaign)
+	}
+
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].ID < campaigns[j].ID
+	})
+
+	return campaigns
+}
+
+// ClearCampaignMedia descarta el file_id de una campaña que Telegram ya no acepta, desde ahi usa la media por defecto
+func (p *Params) ClearCampaignMedia(id int64, fileID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	campaign, exist := p.campaigns[id]
+	if exist && campaign.MediaFileID == fileID {
+		campaign.MediaFileID = ""
+		campaign.MediaType = ""
+	}
+}
+
+// Pick elige el anuncio de un mensaje del grupo. Las campañas activas se eligen al azar segun su peso
+// y si no hay ninguna se usa el anuncio por defecto.
+func (p *Params) Pick(groupID string, now time.Time) Ad {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	live := make([]*Campaign, 0)
+	var total int64
+
+	for _, campaign := range p.campaigns {
+		if campaign.Live(groupID, now.Unix()) {
+			live = append(live, campaign)
+			total += campaign.Weight
+		}
+	}
+
+	if total == 0 {
+		return Ad{
+			AdName:     p.AdName,
+			ButtonName: p.ButtonName,
+			ButtonLink: p.ButtonLink,
+		}
+	}
+
+	// El orden del map cambia en cada recorrido, se ordena para que el sorteo solo dependa del numero elegido
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].ID < live[j].ID
+	})
+
+	pick := rand.Int63n(total)
+
+	chosen := live[len(live)-1]
+	for _, campaign := range live {
+		if pick < campaign.Weight {
+			chosen = campaign
+			break
+		}
+		pick -= campaign.Weight
+	}
+
+	return Ad{
+		CampaignID:  chosen.ID,
+		AdName:      chosen.AdName,
+		ButtonName:  chosen.ButtonName,
+		ButtonLink:  chosen.ButtonLink,
+		MediaFileID: chosen.MediaFileID,
+		MediaType:   chosen.MediaType,
+	}
+}
+
+// Link devuelve el link del boton de la campaña, o el del anuncio por defecto si la campaña es 0 o ya no existe
+func (p *Params) Link(campaignID int64) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if campaign, exist := p.campaigns[campaignID]; exist {
+		return campaign.ButtonLink
+	}
+
+	return p.ButtonLink
+}
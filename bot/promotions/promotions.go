@@ -11,33 +11,55 @@ const (
 	defaultAdName     = "\n***AD SPACE***\n"
 	defaultButtonName = "ADVERTISE HERE"
 	defaultButtonLink = "https://t.me/KiloTonCoin"
+
+	// Tipos de media de los anuncios de compra, MediaNone envia solo el texto
+	MediaVideo     = "video"
+	MediaPhoto     = "photo"
+	MediaAnimation = "animation"
+	MediaNone      = "none"
 )
 
 var (
 	errEmptyString      = errors.New("error: empty param")
-	errInvalidTimestamp = errors.New("error: marca de tiempo invalida")
+	errInvalidMediaType = errors.New("error: tipo de media invalido")
 )
 
+// Params es el anuncio por defecto, se muestra cuando no hay ninguna campaña activa para el grupo
 type Params struct {
-	Media      string
-	AdName     string
-	ButtonName string
-	ButtonLink string
-	Timestamp  int64
-	mutex      sync.RWMutex
+	Media string
+	// MediaFileID es el file_id de Telegram de Media, permite reenviarla sin volver a subir el archivo
+	MediaFileID string
+	MediaType   string
+	AdName      string
+	ButtonName  string
+	ButtonLink  string
+	campaigns   map[int64]*Campaign
+	// lastCampaignID es el ultimo id asignado, nunca baja para que una campaña nueva no herede las estadisticas de otra
+	lastCampaignID int64
+	mutex          sync.RWMutex
 }
 
 func InitParams() *Params {
 	return &Params{
 		Media:      fmt.Sprintf(mediaDefaultPath+"%s", "default.mp4"),
+		MediaType:  MediaVideo,
 		AdName:     defaultAdName,
 		ButtonName: defaultButtonName,
 		ButtonLink: defaultButtonLink,
-		Timestamp:  0,
+		campaigns:  make(map[int64]*Campaign),
 	}
 }
 
-func (p *Params) UpdateMedia(mediaName string) error {
+func ValidMediaType(mediaType string) bool {
+	switch mediaType {
+	case MediaVideo, MediaPhoto, MediaAnimation, MediaNone:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Params) UpdateMedia(mediaName string, mediaType string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
@@ -45,11 +67,54 @@ func (p *Params) UpdateMedia(mediaName string) error {
 		return errEmptyString
 	}
 
+	if !ValidMediaType(mediaType) {
+		return errInvalidMediaType
+	}
+
 	p.Media = fmt.Sprintf(mediaDefaultPath+"%s", mediaName)
+	p.MediaFileID = ""
+	p.MediaType = mediaType
 
 	return nil
 }
 
+// DisableMedia deja los anuncios de compra solo con texto
+func (p *Params) DisableMedia() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.MediaFileID = ""
+	p.MediaType = MediaNone
+}
+
+// GetMedia devuelve la ruta local de la media, su file_id, vacio si todavia no se subio, y su tipo
+func (p *Params) GetMedia() (string, string, string) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.Media, p.MediaFileID, p.MediaType
+}
+
+// CacheMediaFileID guarda el file_id de la media solo si la media no cambio mientras se subia
+func (p *Params) CacheMediaFileID(media string, fileID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Media == media {
+		p.MediaFileID = fileID
+	}
+}
+
+// ClearMediaFileID descarta un file_id que Telegram ya no acepta
+func (p *Params) ClearMediaFileID(fileID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.MediaFileID == fileID {
+		p.MediaFileID = ""
+	}
+}
+
 func (p *Params) UpdateAdName(adName string) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
@@ -88,16 +153,3 @@ func (p *Params) UpdateButtonLink(buttonLink string) error {
 
 	return nil
 }
-
-func (p *Params) SetTimestamp(timestamp int64) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if timestamp == 0 {
-		return errInvalidTimestamp
-	}
-
-	p.Timestamp = timestamp
-
-	return nil
-}
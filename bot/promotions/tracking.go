@@ -0,0 +1,167 @@
+package promotions
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clickPath es la ruta del redireccionador, seguida del id de campaña y el id del grupo
+const clickPath = "/c/"
+
+// AdStat son las veces que se mostro y se presiono el boton de un anuncio en un grupo. CampaignID 0 es el anuncio por defecto.
+type AdStat struct {
+	CampaignID  int64
+	GroupID     string
+	Impressions int64
+	Clicks      int64
+}
+
+type statKey struct {
+	campaignID int64
+	groupID    string
+}
+
+// Tracker cuenta las impresiones de los anuncios y, si tiene BaseURL, los clicks que pasan por el redireccionador
+type Tracker struct {
+	// BaseURL es la direccion publica del redireccionador, vacia envia el boton directo al link del anuncio
+	BaseURL string
+	// Addr es la direccion local donde escucha el redireccionador
+	Addr string
+
+	stats map[statKey]*AdStat
+	mutex sync.RWMutex
+}
+
+func InitTracker(baseURL string, addr string) *Tracker {
+	return &Tracker{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Addr:    addr,
+		stats:   make(map[statKey]*AdStat),
+	}
+}
+
+// Link devuelve el link del boton del anuncio, pasando por el redireccionador cuando esta configurado
+func (t *Tracker) Link(ad Ad, groupID string) string {
+	if t.BaseURL == "" {
+		return ad.ButtonLink
+	}
+
+	return fmt.Sprintf("%s%s%d/%s", t.BaseURL, clickPath, ad.CampaignID, url.PathEscape(groupID))
+}
+
+func (t *Tracker) Impression(campaignID int64, groupID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := statKey{campaignID: campaignID, groupID: groupID}
+
+	stat, exist := t.stats[key]
+	if !exist {
+		stat = &AdStat{CampaignID: campaignID, GroupID: groupID}
+		t.stats[key] = stat
+	}
+
+	stat.Impressions++
+}
+
+// Click registra un click, solo cuenta si el anuncio ya se mostro en ese grupo para que un link inventado no agregue entradas
+func (t *Tracker) Click(campaignID int64, groupID string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	stat, exist := t.stats[statKey{campaignID: campaignID, groupID: groupID}]
+	if !exist {
+		return false
+	}
+
+	stat.Clicks++
+
+	return true
+}
+
+// Restore agrega las estadisticas guardadas en la base de datos
+func (t *Tracker) Restore(stat AdStat) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stats[statKey{campaignID: stat.CampaignID, groupID: stat.GroupID}] = &stat
+}
+
+// Stats devuelve una copia de las estadisticas ordenadas por campaña y por impresiones
+func (t *Tracker) Stats() []AdStat {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	stats := make([]AdStat, 0, len(t.stats))
+	for _, stat := range t.stats {
+		stats = append(stats, *stat)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].CampaignID != stats[j].CampaignID {
+			return stats[i].CampaignID < stats[j].CampaignID
+		}
+		if stats[i].Impressions != stats[j].Impressions {
+			return stats[i].Impressions > stats[j].Impressions
+		}
+		return stats[i].GroupID < stats[j].GroupID
+	})
+
+	return stats
+}
+
+// ClickHandler registra el click y redirige al link actual de la campaña, o al del anuncio por defecto si la campaña ya no existe.
+// El destino nunca sale de la URL para que el redireccionador no se pueda usar hacia sitios arbitrarios.
+func (t *Tracker) ClickHandler(promo *Params) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		campaignValue, groupID, found := strings.Cut(strings.TrimPrefix(r.URL.Path, clickPath), "/")
+		if !found || !strings.HasPrefix(r.URL.Path, clickPath) {
+			http.NotFound(w, r)
+			return
+		}
+
+		campaignID, err := strconv.ParseInt(campaignValue, 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		link := promo.Link(campaignID)
+		if link == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		t.Click(campaignID, groupID)
+
+		http.Redirect(w, r, link, http.StatusFound)
+	})
+}
+
+// HandleClicks inicia el redireccionador, no hace nada si no esta configurado
+func (t *Tracker) HandleClicks(promo *Params) {
+	if t.BaseURL == "" || t.Addr == "" {
+		log.Print("no hay una URL de seguimiento configurada, los clicks de los anuncios no se cuentan")
+		return
+	}
+
+	server := &http.Server{
+		Addr:              t.Addr,
+		Handler:           t.ClickHandler(promo),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("redireccionador de anuncios escuchando en %s", t.Addr)
+
+	err := server.ListenAndServe()
+	if err != nil {
+		log.Printf("el redireccionador de anuncios se detuvo: %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package sender
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	// Limites de Telegram: 30 mensajes por segundo en total, 20 por minuto en un grupo y 1 por segundo en un chat privado
+	globalInterval  = time.Second / 30
+	groupInterval   = 3 * time.Second
+	privateInterval = time.Second
+
+	// idleTimeout es el tiempo sin mensajes tras el cual se cierra la goroutine de un chat
+	idleTimeout = 10 * time.Minute
+
+	maxAttempts  = 4
+	baseBackoff  = time.Second
+	queueSize    = 256
+	notModified  = "message is not modified"
+	resultBuffer = 1
+)
+
+// ErrQueueFull se devuelve cuando la cola del chat esta llena, el mensaje se descarta en lugar de bloquear al que lo envia
+var ErrQueueFull = errors.New("error: la cola de envios del chat esta llena")
+
+// Client es la parte de la API de Telegram que usa la cola, *tgbotapi.BotAPI la implementa
+type Client interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+// limits son los tiempos de la cola, retryUnit es la unidad del retry_after de Telegram
+type limits struct {
+	global    time.Duration
+	group     time.Duration
+	private   time.Duration
+	backoff   time.Duration
+	retryUnit time.Duration
+	idle      time.Duration
+}
+
+var telegramLimits = limits{
+	global:    globalInterval,
+	group:     groupInterval,
+	private:   privateInterval,
+	backoff:   baseBackoff,
+	retryUnit: time.Second,
+	idle:      idleTimeout,
+}
+
+// Priority ordena los mensajes pendientes de un mismo chat, los de prioridad alta salen primero
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh
+)
+
+type result struct {
+	message tgbotapi.Message
+	err     error
+}
+
+type job struct {
+	chatID   int64
+	msg      tgbotapi.Chattable
+	attempts int
+	result   chan result
+}
+
+// chatQueue es la cola de salida de un chat, la procesa su propia goroutine
+type chatQueue struct {
+	high   chan *job
+	normal chan *job
+}
+
+// Queue centraliza todos los envios a Telegram respetando los limites por chat y globales
+type Queue struct {
+	client Client
+	limits limits
+
+	chats  map[int64]*chatQueue
+	global *time.Ticker
+	mutex  sync.Mutex
+}
+
+func Init(bot *tgbotapi.BotAPI) *Queue {
+	return newQueue(bot, telegramLimits)
+}
+
+func newQueue(client Client, limits limits) *Queue {
+	return &Queue{
+		client: client,
+		limits: limits,
+		chats:  make(map[int64]*chatQueue),
+		global: time.NewTicker(limits.global),
+	}
+}
+
+// Send encola un mensaje sin esperar a que se envie. Devuelve ErrQueueFull si la cola del chat esta llena.
+func (q *Queue) Send(chatID int64, msg tgbotapi.Chattable) error {
+	return q.enqueue(chatID, msg, PriorityNormal, nil)
+}
+
+// SendPriority encola un mensaje que debe salir antes que los pendientes del chat, como el fin de una competencia
+func (q *Queue) SendPriority(chatID int64, msg tgbotapi.Chattable) error {
+	return q.enqueue(chatID, msg, PriorityHigh, nil)
+}
+
+// SendWait encola un mensaje y espera el resultado, para los casos que necesitan el mensaje enviado
+func (q *Queue) SendWait(chatID int64, msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+	done := make(chan result, resultBuffer)
+
+	err := q.enqueue(chatID, msg, PriorityNormal, done)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	res := <-done
+	return res.message, res.err
+}
+
+func (q *Queue) enqueue(chatID int64, msg tgbotapi.Chattable, priority Priority, done chan result) error {
+	// El lock se mantiene hasta encolar para que el worker no se cierre por inactividad entre medio
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	chat := q.chatQueue(chatID)
+
+	newJob := &job{
+		chatID: chatID,
+		msg:    msg,
+		result: done,
+	}
+
+	pending := chat.normal
+	if priority == PriorityHigh {
+		pending = chat.high
+	}
+
+	select {
+	case pending <- newJob:
+		return nil
+	default:
+		log.Printf("se descarto un mensaje para el chat %d, la cola esta llena", chatID)
+		return ErrQueueFull
+	}
+}
+
+// chatQueue devuelve la cola del chat y la crea con su worker si no existe, se llama con q.mutex tomado
+func (q *Queue) chatQueue(chatID int64) *chatQueue {
+	chat, exist := q.chats[chatID]
+	if exist {
+		return chat
+	}
+
+	chat = &chatQueue{
+		high:   make(chan *job, queueSize),
+		normal: make(chan *job, queueSize),
+	}
+	q.chats[chatID] = chat
+
+	go q.worker(chatID, chat)
+
+	return chat
+}
+
+func (q *Queue) worker(chatID int64, chat *chatQueue) {
+	interval := q.limits.private
+	if chatID < 0 {
+		interval = q.limits.group
+	}
+
+	var last time.Time
+
+	idle := time.NewTimer(q.limits.idle)
+	defer idle.Stop()
+
+	for {
+		var next *job
+
+		// Primero se vacian los mensajes de prioridad alta
+		select {
+		case next = <-chat.high:
+		default:
+			select {
+			case next = <-chat.high:
+			case next = <-chat.normal:
+			case <-idle.C:
+				if q.closeIdle(chatID, chat) {
+					return
+				}
+				idle.Reset(q.limits.idle)
+				continue
+			}
+		}
+
+		if wait := interval - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		q.deliver(next)
+		last = time.Now()
+
+		if !idle.Stop() {
+			<-idle.C
+		}
+		idle.Reset(q.limits.idle)
+	}
+}
+
+// closeIdle quita la cola de un chat sin mensajes pendientes, asi los grupos que el bot dejo y los chats privados
+// no mantienen una goroutine abierta. Devuelve false si llego un mensaje mientras tanto.
+func (q *Queue) closeIdle(chatID int64, chat *chatQueue) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(chat.high) > 0 || len(chat.normal) > 0 {
+		return false
+	}
+
+	delete(q.chats, chatID)
+
+	return true
+}
+
+// deliver envia el mensaje reintentando con backoff y respetando el retry_after de Telegram
+func (q *Queue) deliver(next *job) {
+	for {
+		<-q.global.C
+
+		message, err := q.client.Send(next.msg)
+		next.attempts++
+
+		if err == nil {
+			next.done(message, nil)
+			return
+		}
+
+		if next.attempts >= maxAttempts {
+			log.Printf("se descarto un mensaje para el chat %d tras %d intentos: %v", next.chatID, next.attempts, err)
+			next.done(message, err)
+			return
+		}
+
+		if apiErr, ok := err.(tgbotapi.Error); ok {
+			if apiErr.RetryAfter > 0 {
+				log.Printf("limite de envios en el chat %d, reintentando en %d segundos", next.chatID, apiErr.RetryAfter)
+				time.Sleep(time.Duration(apiErr.RetryAfter) * q.limits.retryUnit)
+				continue
+			}
+
+			// Telegram rechazo el mensaje, reintentarlo daria el mismo error
+			if !strings.Contains(apiErr.Message, notModified) {
+				log.Printf("error al enviar mensaje al chat %d: %v", next.chatID, err)
+			}
+			next.done(message, err)
+			return
+		}
+
+		backoff := q.limits.backoff * time.Duration(1<<(next.attempts-1))
+		log.Printf("error de red al enviar mensaje al chat %d, reintentando en %s: %v", next.chatID, backoff, err)
+		time.Sleep(backoff)
+	}
+}
+
+func (j *job) done(message tgbotapi.Message, err error) {
+	if j.result != nil {
+		j.result <- result{message: message, err: err}
+	}
+}
@@ -0,0 +1,254 @@
+package sender
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var testLimits = limits{
+	global:    5 * time.Millisecond,
+	group:     40 * time.Millisecond,
+	private:   20 * time.Millisecond,
+	backoff:   time.Millisecond,
+	retryUnit: 30 * time.Millisecond,
+	idle:      time.Minute,
+}
+
+var errNetwork = errors.New("connection reset")
+
+type sent struct {
+	chatID int64
+	text   string
+	at     time.Time
+}
+
+// fakeClient registra los envios y devuelve los errores programados para cada texto, en orden
+type fakeClient struct {
+	mutex  sync.Mutex
+	sent   []sent
+	errors map[string][]error
+
+	// gate, si no es nil, retiene el envio de "hold" hasta que se cierra
+	gate    chan struct{}
+	holding chan struct{}
+}
+
+func (f *fakeClient) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg := c.(tgbotapi.MessageConfig)
+
+	if msg.Text == "hold" && f.gate != nil {
+		close(f.holding)
+		<-f.gate
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.sent = append(f.sent, sent{chatID: msg.ChatID, text: msg.Text, at: time.Now()})
+
+	if pending := f.errors[msg.Text]; len(pending) > 0 {
+		f.errors[msg.Text] = pending[1:]
+		return tgbotapi.Message{}, pending[0]
+	}
+
+	return tgbotapi.Message{Text: msg.Text}, nil
+}
+
+func (f *fakeClient) calls() []sent {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]sent(nil), f.sent...)
+}
+
+func retryAfter(seconds int) error {
+	return tgbotapi.Error{Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: seconds}}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		errors   []error
+		attempts int
+		failed   bool
+		minTime  time.Duration
+	}{
+		{name: "success", attempts: 1},
+		{name: "retry after", errors: []error{retryAfter(2)}, attempts: 2, minTime: 2 * testLimits.retryUnit},
+		{name: "network error", errors: []error{errNetwork, errNetwork}, attempts: 3},
+		{name: "gives up after max attempts", errors: []error{errNetwork, errNetwork, errNetwork, errNetwork, errNetwork}, attempts: maxAttempts, failed: true},
+		{name: "retry after counts as attempt", errors: []error{retryAfter(1), retryAfter(1), retryAfter(1), retryAfter(1)}, attempts: maxAttempts, failed: true},
+		{name: "api error is not retried", errors: []error{tgbotapi.Error{Message: "Bad Request: chat not found"}}, attempts: 1, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{errors: map[string][]error{"hello": tt.errors}}
+			queue := newQueue(client, testLimits)
+
+			start := time.Now()
+			_, err := queue.SendWait(1, tgbotapi.NewMessage(1, "hello"))
+
+			if tt.failed && err == nil {
+				t.Errorf("expected an error")
+			}
+			if !tt.failed && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if got := len(client.calls()); got != tt.attempts {
+				t.Errorf("sent %d times, expected %d", got, tt.attempts)
+			}
+
+			if elapsed := time.Since(start); elapsed < tt.minTime {
+				t.Errorf("delivered after %s, expected to wait at least %s", elapsed, tt.minTime)
+			}
+		})
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		chats  []int64
+		minGap time.Duration
+	}{
+		{name: "private chat", chats: []int64{1, 1, 1}, minGap: testLimits.private},
+		{name: "group chat", chats: []int64{-1, -1, -1}, minGap: testLimits.group},
+		{name: "global", chats: []int64{1, 2, 3, 4, 5}, minGap: testLimits.global},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{}
+			queue := newQueue(client, testLimits)
+
+			var wg sync.WaitGroup
+			for _, chatID := range tt.chats {
+				wg.Add(1)
+				go func(chatID int64) {
+					defer wg.Done()
+					queue.SendWait(chatID, tgbotapi.NewMessage(chatID, "hello"))
+				}(chatID)
+			}
+			wg.Wait()
+
+			calls := client.calls()
+			if len(calls) != len(tt.chats) {
+				t.Fatalf("sent %d messages, expected %d", len(calls), len(tt.chats))
+			}
+
+			// Se deja un margen pequeño porque el ticker global puede adelantar un tick
+			tolerance := testLimits.global / 2
+			for i := 1; i < len(calls); i++ {
+				if gap := calls[i].at.Sub(calls[i-1].at); gap < tt.minGap-tolerance {
+					t.Errorf("messages %d and %d sent %s apart, expected at least %s", i-1, i, gap, tt.minGap)
+				}
+			}
+		})
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	client := &fakeClient{gate: make(chan struct{}), holding: make(chan struct{})}
+	queue := newQueue(client, testLimits)
+
+	queue.Send(1, tgbotapi.NewMessage(1, "hold"))
+	<-client.holding
+
+	queue.Send(1, tgbotapi.NewMessage(1, "first"))
+	queue.Send(1, tgbotapi.NewMessage(1, "second"))
+	queue.SendPriority(1, tgbotapi.NewMessage(1, "urgent"))
+	close(client.gate)
+
+	queue.SendWait(1, tgbotapi.NewMessage(1, "last"))
+
+	expected := []string{"hold", "urgent", "first", "second", "last"}
+	calls := client.calls()
+
+	if len(calls) != len(expected) {
+		t.Fatalf("sent %d messages, expected %d", len(calls), len(expected))
+	}
+
+	for i, text := range expected {
+		if calls[i].text != text {
+			t.Errorf("message %d is %q, expected %q", i, calls[i].text, text)
+		}
+	}
+}
+
+func TestSendDoesNotBlockWhenQueueIsFull(t *testing.T) {
+	client := &fakeClient{gate: make(chan struct{}), holding: make(chan struct{})}
+	queue := newQueue(client, testLimits)
+	defer close(client.gate)
+
+	queue.Send(1, tgbotapi.NewMessage(1, "hold"))
+	<-client.holding
+
+	for i := 0; i < queueSize; i++ {
+		if err := queue.Send(1, tgbotapi.NewMessage(1, "hello")); err != nil {
+			t.Fatalf("message %d rejected: %v", i, err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- queue.Send(1, tgbotapi.NewMessage(1, "hello"))
+	}()
+
+	select {
+	case err := <-done:
+		if err != ErrQueueFull {
+			t.Errorf("expected ErrQueueFull, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Send blocked with a full queue")
+	}
+
+	if _, err := queue.SendWait(1, tgbotapi.NewMessage(1, "hello")); err != ErrQueueFull {
+		t.Errorf("expected SendWait to return ErrQueueFull, got %v", err)
+	}
+
+	if err := queue.SendPriority(1, tgbotapi.NewMessage(1, "urgent")); err != nil {
+		t.Errorf("the high priority queue should still accept messages: %v", err)
+	}
+}
+
+func TestIdleWorkerIsRemoved(t *testing.T) {
+	client := &fakeClient{}
+	idleLimits := testLimits
+	idleLimits.idle = 20 * time.Millisecond
+	queue := newQueue(client, idleLimits)
+
+	if _, err := queue.SendWait(1, tgbotapi.NewMessage(1, "hello")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		queue.mutex.Lock()
+		_, exist := queue.chats[1]
+		queue.mutex.Unlock()
+
+		if !exist {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("the idle chat queue was not removed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := queue.SendWait(1, tgbotapi.NewMessage(1, "again")); err != nil {
+		t.Fatalf("sending after the worker closed failed: %v", err)
+	}
+
+	if got := len(client.calls()); got != 2 {
+		t.Errorf("sent %d messages, expected 2", got)
+	}
+}
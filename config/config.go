@@ -3,18 +3,34 @@ package config
 import (
 	"errors"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
+	"github.com/polarysfoundation/kilocompbot/bot/messages"
 )
 
 var (
 	errorbotTokenNotExist = errors.New("error: el token del bot no existe")
 	errorEnvFileNotExist  = errors.New("error: el archivo env no existe, o hubo error al cargarlo")
+	errorInvalidAdminID   = errors.New("error: SUPER_ADMINS debe ser una lista de ids de usuario de Telegram separados por coma")
 )
 
+// defaultTrackingAddr es donde escucha el redireccionador de clicks si no se define TRACKING_ADDR
+const defaultTrackingAddr = ":8080"
+
 type Config struct {
 	BotToken     string
 	TONCenterAPI string
+	TemplatesDir string
+	SuperAdmins  []int64
+	// PaymentAddress recibe los pagos de los anuncios, vacia desactiva las reservas con /advertise
+	PaymentAddress string
+	AdSlots        []bookings.Slot
+	// TrackingURL es la URL publica del redireccionador de clicks y TrackingAddr donde escucha, sin URL no se cuentan los clicks
+	TrackingURL  string
+	TrackingAddr string
 }
 
 func Init() (*Config, error) {
@@ -33,8 +49,62 @@ func Init() (*Config, error) {
 		return nil, errorbotTokenNotExist
 	}
 
+	// Directorio con las plantillas que reemplazan los mensajes por defecto
+	templatesDir := os.Getenv("TEMPLATES_DIR")
+	if templatesDir == "" {
+		templatesDir = messages.DefaultDir
+	}
+
+	// Ids de Telegram de los super administradores, se registran en la base de datos al iniciar
+	superAdmins, err := parseIDs(os.Getenv("SUPER_ADMINS"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Duraciones y precios en TON de los anuncios, por ejemplo 1d=10,3d=25
+	adSlots := os.Getenv("AD_SLOTS")
+	if adSlots == "" {
+		adSlots = bookings.DefaultSlots
+	}
+
+	slots, err := bookings.ParseSlots(adSlots)
+	if err != nil {
+		return nil, err
+	}
+
+	trackingAddr := os.Getenv("TRACKING_ADDR")
+	if trackingAddr == "" {
+		trackingAddr = defaultTrackingAddr
+	}
+
 	return &Config{
-		BotToken:     telegramToken,
-		TONCenterAPI: tonAPI,
+		BotToken:       telegramToken,
+		TONCenterAPI:   tonAPI,
+		TemplatesDir:   templatesDir,
+		SuperAdmins:    superAdmins,
+		PaymentAddress: strings.TrimSpace(os.Getenv("PAYMENT_ADDRESS")),
+		AdSlots:        slots,
+		TrackingURL:    strings.TrimSpace(os.Getenv("TRACKING_URL")),
+		TrackingAddr:   trackingAddr,
 	}, nil
 }
+
+func parseIDs(value string) ([]int64, error) {
+	ids := make([]int64, 0)
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errorInvalidAdminID
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
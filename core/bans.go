@@ -0,0 +1,92 @@
+package core
+
+import "time"
+
+// Ban descalifica una wallet de la competencia actual y retira sus compras del ranking
+func (c *Competition) Ban(id string, wallet string, reason string, by string) (*Disqualification, error) {
+	if id == "" || wallet == "" {
+		return nil, errrorEmptyID
+	}
+
+	if !c.BlackListExist(id) {
+		err := c.NewBlacklist(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	blacklist, err := c.GetBlacklist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if blacklist.IsDisqualified(wallet) {
+		return nil, errorAlreadyDisqualified
+	}
+
+	disqualification := &Disqualification{
+		Wallet:    wallet,
+		Reason:    reason,
+		By:        by,
+		Timestamp: time.Now().Unix(),
+		Purchases: make([]*Purchase, 0),
+	}
+
+	if purchases, err := c.GetComp(id); err == nil {
+		disqualification.Purchases = purchases.RemovePurchasesByBuyer(wallet)
+	}
+
+	err = blacklist.Disqualify(disqualification)
+	if err != nil {
+		return nil, err
+	}
+
+	return disqualification, nil
+}
+
+// Unban reincorpora una wallet descalificada y le devuelve las compras retiradas
+func (c *Competition) Unban(id string, wallet string) (*Disqualification, error) {
+	blacklist, err := c.GetBlacklist(id)
+	if err != nil {
+		return nil, errorNotDisqualified
+	}
+
+	disqualification, err := blacklist.Reinstate(wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(disqualification.Purchases) == 0 {
+		return disqualification, nil
+	}
+
+	if !c.CompExist(id) {
+		err := c.NewComp(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	purchases, err := c.GetComp(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, purchase := range disqualification.Purchases {
+		err := purchases.StorePurchase(purchase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return disqualification, nil
+}
+
+func (c *Competition) Banned(id string) []*Disqualification {
+	blacklist, err := c.GetBlacklist(id)
+	if err != nil {
+		return nil
+	}
+
+	return blacklist.DisqualifiedList()
+}
@@ -20,7 +20,9 @@ type Competition struct {
 	Comps     map[string]*Purchases
 	BlackList map[string]*Sales
 	Timestamp map[string]int64
-	mutex     sync.RWMutex
+	// Paused guarda el momento en que se pauso la competencia de cada grupo
+	Paused map[string]int64
+	mutex  sync.RWMutex
 }
 
 func InitComp() *Competition {
@@ -28,6 +30,7 @@ func InitComp() *Competition {
 		Comps:     make(map[string]*Purchases),
 		BlackList: make(map[string]*Sales),
 		Timestamp: make(map[string]int64),
+		Paused:    make(map[string]int64),
 	}
 }
 
@@ -151,9 +154,7 @@ func (c *Competition) NewBlacklist(id string) error {
 		return errorBlacklistAlreadyExist
 	}
 
-	newSale := &Sales{
-		Sale: make(map[string]*Sale),
-	}
+	newSale := InitSales()
 
 	c.BlackList[id] = newSale
 
@@ -224,6 +225,7 @@ func (c *Competition) RemoveTimestampActive(id string) error {
 	}
 
 	delete(c.Timestamp, id)
+	delete(c.Paused, id)
 
 	return nil
 }
@@ -232,6 +234,11 @@ func (c *Competition) IsEnded(id string) bool {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
+	// El tiempo en pausa no cuenta, la competencia no termina mientras este pausada
+	if _, paused := c.Paused[id]; paused {
+		return false
+	}
+
 	crrTime := time.Now().Unix()
 
 	timestamp := c.Timestamp[id]
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	MinCompDuration = 1 * time.Minute
+	MaxCompDuration = 30 * 24 * time.Hour
+
+	// DefaultReminders son los avisos de tiempo restante de un grupo nuevo
+	DefaultReminders = "24h,6h,1h,10m"
+
+	utcZone = "utc"
+)
+
+var (
+	errorInvalidDuration = errors.New("error: duracion invalida")
+	errorDurationRange   = errors.New("error: duracion fuera de rango")
+	errorInvalidDate     = errors.New("error: fecha invalida")
+	errorDateInPast      = errors.New("error: la fecha ya paso")
+)
+
+// dateLayouts solo admiten offsets numericos, el layout MST de Go acepta cualquier abreviatura como CET o EST
+// y la interpreta como UTC sin avisar. La zona UTC se quita antes de interpretar la fecha.
+var dateLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04 -07:00",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05Z07:00",
+}
+
+// ParseDuration acepta duraciones como 36h, 2d12h o 90m. Un numero sin unidad se interpreta en horas, como las
+// respuestas 24, 48 y 72 que se aceptaban antes.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+	if value == "" {
+		return 0, errorInvalidDuration
+	}
+
+	if hours, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return checkRange(time.Duration(hours) * time.Hour)
+	}
+
+	var total time.Duration
+	number := ""
+
+	for _, char := range value {
+		if unicode.IsDigit(char) {
+			number += string(char)
+			continue
+		}
+
+		if number == "" {
+			return 0, errorInvalidDuration
+		}
+
+		amount, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return 0, errorInvalidDuration
+		}
+
+		switch char {
+		case 'd':
+			total += time.Duration(amount) * 24 * time.Hour
+		case 'h':
+			total += time.Duration(amount) * time.Hour
+		case 'm':
+			total += time.Duration(amount) * time.Minute
+		default:
+			return 0, errorInvalidDuration
+		}
+
+		number = ""
+	}
+
+	if number != "" {
+		return 0, errorInvalidDuration
+	}
+
+	return checkRange(total)
+}
+
+// ParseDate interpreta una fecha absoluta como "2026-11-01 18:00 UTC" o "2026-11-01 18:00 +03:00".
+// Sin zona horaria se asume UTC, otras abreviaturas de zona se rechazan.
+func ParseDate(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > len(utcZone) && strings.EqualFold(value[len(value)-len(utcZone):], utcZone) {
+		value = strings.TrimSpace(value[:len(value)-len(utcZone)])
+	}
+
+	for _, layout := range dateLayouts {
+		date, err := time.ParseInLocation(layout, value, time.UTC)
+		if err != nil {
+			continue
+		}
+
+		if !date.After(now) {
+			return time.Time{}, errorDateInPast
+		}
+
+		return date, nil
+	}
+
+	return time.Time{}, errorInvalidDate
+}
+
+// ParseEndTime devuelve el timestamp de culminacion a partir de una duracion o de una fecha absoluta
+func ParseEndTime(value string, now time.Time) (int64, error) {
+	date, err := ParseDate(value, now)
+	if err == nil {
+		if _, err := checkRange(date.Sub(now)); err != nil {
+			return 0, err
+		}
+		return date.Unix(), nil
+	}
+
+	if err == errorDateInPast {
+		return 0, err
+	}
+
+	duration, err := ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return now.Add(duration).Unix(), nil
+}
+
+// FormatDuration muestra una duracion como "2d 12h 30m"
+func FormatDuration(duration time.Duration) string {
+	if duration < 0 {
+		duration = 0
+	}
+
+	days := int64(duration.Hours()) / 24
+	hours := int64(duration.Hours()) % 24
+	minutes := int64(duration.Minutes()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// ParseReminders interpreta una lista de avisos separada por comas y la devuelve de mayor a menor.
+// "off" o una lista vacia desactivan los avisos.
+func ParseReminders(value string) ([]time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "off" {
+		return nil, nil
+	}
+
+	reminders := make([]time.Duration, 0)
+	seen := make(map[time.Duration]bool)
+
+	for _, item := range strings.Split(value, ",") {
+		reminder, err := ParseDuration(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[reminder] {
+			continue
+		}
+		seen[reminder] = true
+
+		reminders = append(reminders, reminder)
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i] > reminders[j]
+	})
+
+	return reminders, nil
+}
+
+// FormatReminders normaliza una lista de avisos para guardarla, por ejemplo "24h,6h,1h,10m"
+func FormatReminders(reminders []time.Duration) string {
+	if len(reminders) == 0 {
+		return "off"
+	}
+
+	items := make([]string, 0, len(reminders))
+	for _, reminder := range reminders {
+		items = append(items, compactDuration(reminder))
+	}
+
+	return strings.Join(items, ",")
+}
+
+func compactDuration(duration time.Duration) string {
+	days := int64(duration.Hours()) / 24
+	hours := int64(duration.Hours()) % 24
+	minutes := int64(duration.Minutes()) % 60
+
+	compact := ""
+	if days > 0 {
+		compact += fmt.Sprintf("%dd", days)
+	}
+	if hours > 0 {
+		compact += fmt.Sprintf("%dh", hours)
+	}
+	if minutes > 0 || compact == "" {
+		compact += fmt.Sprintf("%dm", minutes)
+	}
+
+	return compact
+}
+
+func checkRange(duration time.Duration) (time.Duration, error) {
+	if duration < MinCompDuration || duration > MaxCompDuration {
+		return 0, errorDurationRange
+	}
+
+	return duration, nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		err      error
+	}{
+		{value: "24h", expected: 24 * time.Hour},
+		{value: "2d12h", expected: 60 * time.Hour},
+		{value: "90m", expected: 90 * time.Minute},
+		{value: " 1D 6H ", expected: 30 * time.Hour},
+		{value: "1h30m", expected: 90 * time.Minute},
+		{value: "24", expected: 24 * time.Hour},
+		{value: "72", expected: 72 * time.Hour},
+		{value: "5", expected: 5 * time.Hour},
+		{value: "721", err: errorDurationRange},
+		{value: "12h30", err: errorInvalidDuration},
+		{value: "h", err: errorInvalidDuration},
+		{value: "2w", err: errorInvalidDuration},
+		{value: "", err: errorInvalidDuration},
+		{value: "0m", err: errorDurationRange},
+		{value: "31d", err: errorDurationRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			duration, err := ParseDuration(tt.value)
+			if err != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if duration != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, duration)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+		err      error
+	}{
+		{value: "2026-11-01 18:00 UTC", expected: time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01 18:00 utc", expected: time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01 18:00", expected: time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01 18:00:30", expected: time.Date(2026, 11, 1, 18, 0, 30, 0, time.UTC)},
+		{value: "2026-11-01 18:00 +03:00", expected: time.Date(2026, 11, 1, 15, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01 18:00 -0500", expected: time.Date(2026, 11, 1, 23, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01T18:00Z", expected: time.Date(2026, 11, 1, 18, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01T18:00:00+01:00", expected: time.Date(2026, 11, 1, 17, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01 18:00 CET", err: errorInvalidDate},
+		{value: "2026-11-01 18:00 EST", err: errorInvalidDate},
+		{value: "2026-11-01 18:00 GMT", err: errorInvalidDate},
+		{value: "tomorrow", err: errorInvalidDate},
+		{value: "2026-09-01 18:00 UTC", err: errorDateInPast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			date, err := ParseDate(tt.value, now)
+			if err != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if !date.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, date)
+			}
+		})
+	}
+}
+
+func TestParseEndTime(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected int64
+		err      error
+	}{
+		{value: "36h", expected: now.Add(36 * time.Hour).Unix()},
+		{value: "2026-10-02 12:00 UTC", expected: now.Add(24 * time.Hour).Unix()},
+		{value: "2026-12-01 12:00 UTC", err: errorDurationRange},
+		{value: "2026-09-30 12:00", err: errorDateInPast},
+		{value: "48", expected: now.Add(48 * time.Hour).Unix()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			end, err := ParseEndTime(tt.value, now)
+			if err != tt.err {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if end != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// GlobalScope identifica la lista de exclusion que aplica a todos los grupos
+const GlobalScope = "global"
+
+var (
+	// ErrExcludedBuyer se devuelve cuando la compra pertenece a una wallet excluida del ranking
+	ErrExcludedBuyer = errors.New("error: la wallet esta excluida de la competencia")
+
+	errorExclusionAlreadyExist = errors.New("error: la wallet ya esta excluida")
+	errorExclusionNotExist     = errors.New("error: la wallet no esta excluida")
+)
+
+// Exclusions guarda las wallets (equipo, market makers, exchanges, pools) que nunca entran al ranking.
+// Las listas se indexan por id de grupo, GlobalScope aplica a todos.
+type Exclusions struct {
+	Wallets map[string]map[string]struct{}
+	mutex   sync.RWMutex
+}
+
+func InitExclusions() *Exclusions {
+	return &Exclusions{
+		Wallets: make(map[string]map[string]struct{}),
+	}
+}
+
+func (e *Exclusions) Add(scope string, wallet string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if scope == "" || wallet == "" {
+		return errrorEmptyID
+	}
+
+	if _, exist := e.Wallets[scope]; !exist {
+		e.Wallets[scope] = make(map[string]struct{})
+	}
+
+	if _, exist := e.Wallets[scope][wallet]; exist {
+		return errorExclusionAlreadyExist
+	}
+
+	e.Wallets[scope][wallet] = struct{}{}
+
+	return nil
+}
+
+func (e *Exclusions) Remove(scope string, wallet string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exist := e.Wallets[scope][wallet]; !exist {
+		return errorExclusionNotExist
+	}
+
+	delete(e.Wallets[scope], wallet)
+
+	return nil
+}
+
+// Clear descarta la lista de exclusion del scope
+func (e *Exclusions) Clear(scope string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	delete(e.Wallets, scope)
+}
+
+// IsExcluded comprueba la lista del grupo y la lista global
+func (e *Exclusions) IsExcluded(id string, wallet string) bool {
+	if e == nil {
+		return false
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if _, exist := e.Wallets[GlobalScope][wallet]; exist {
+		return true
+	}
+
+	_, exist := e.Wallets[id][wallet]
+
+	return exist
+}
+
+func (e *Exclusions) List(scope string) []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	wallets := make([]string, 0, len(e.Wallets[scope]))
+	for wallet := range e.Wallets[scope] {
+		wallets = append(wallets, wallet)
+	}
+
+	sort.Strings(wallets)
+
+	return wallets
+}
+
+func (e *Exclusions) Scopes() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	scopes := make([]string, 0, len(e.Wallets))
+	for scope := range e.Wallets {
+		scopes = append(scopes, scope)
+	}
+
+	return scopes
+}
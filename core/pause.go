@@ -0,0 +1,125 @@
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errorAlreadyPaused = errors.New("error: la competencia ya esta en pausa")
+	errorNotPaused     = errors.New("error: la competencia no esta en pausa")
+)
+
+// AdjustTimestamp mueve la fecha de culminacion de la competencia, delta negativo la acorta
+func (c *Competition) AdjustTimestamp(id string, delta time.Duration) (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if id == "" {
+		return 0, errrorEmptyID
+	}
+
+	timestamp, exist := c.Timestamp[id]
+	if !exist {
+		return 0, errorTimestampNotExist
+	}
+
+	// Mientras esta en pausa el tiempo restante se cuenta desde el momento de la pausa
+	reference := time.Now().Unix()
+	if pausedAt, paused := c.Paused[id]; paused {
+		reference = pausedAt
+	}
+
+	newTimestamp := timestamp + int64(delta.Seconds())
+
+	_, err := checkRange(time.Duration(newTimestamp-reference) * time.Second)
+	if err != nil {
+		return 0, err
+	}
+
+	c.Timestamp[id] = newTimestamp
+
+	return newTimestamp, nil
+}
+
+// Pause detiene el conteo del tiempo de la competencia desde el momento indicado
+func (c *Competition) Pause(id string, at int64) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if id == "" {
+		return errrorEmptyID
+	}
+
+	if _, exist := c.Timestamp[id]; !exist {
+		return errorTimestampNotExist
+	}
+
+	if _, paused := c.Paused[id]; paused {
+		return errorAlreadyPaused
+	}
+
+	c.Paused[id] = at
+
+	return nil
+}
+
+// Resume reanuda la competencia y corre la fecha de culminacion por el tiempo que estuvo en pausa
+func (c *Competition) Resume(id string) (int64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if id == "" {
+		return 0, errrorEmptyID
+	}
+
+	pausedAt, paused := c.Paused[id]
+	if !paused {
+		return 0, errorNotPaused
+	}
+
+	pausedFor := time.Now().Unix() - pausedAt
+	if pausedFor < 0 {
+		pausedFor = 0
+	}
+
+	c.Timestamp[id] += pausedFor
+	delete(c.Paused, id)
+
+	return c.Timestamp[id], nil
+}
+
+func (c *Competition) IsPaused(id string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_, paused := c.Paused[id]
+
+	return paused
+}
+
+// PausedAt devuelve el momento de la pausa, 0 si la competencia no esta en pausa
+func (c *Competition) PausedAt(id string) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.Paused[id]
+}
+
+// TimeLeft devuelve el tiempo restante de la competencia sin contar el tiempo en pausa
+func (c *Competition) TimeLeft(id string) time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	reference := time.Now().Unix()
+	if pausedAt, paused := c.Paused[id]; paused {
+		reference = pausedAt
+	}
+
+	left := c.Timestamp[id] - reference
+	if left < 0 {
+		return 0
+	}
+
+	return time.Duration(left) * time.Second
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+)
+
+const (
+	// SellDisqualify descalifica a la wallet en cuanto vende
+	SellDisqualify = "disqualify"
+	// SellSubtract descuenta el TON vendido del score de la wallet
+	SellSubtract = "subtract"
+	// SellTolerate permite ventas por debajo de un porcentaje de los tokens comprados
+	SellTolerate = "tolerate"
+
+	reasonSold = "sold during the competition"
+)
+
+var errorInvalidPolicy = errors.New("error: politica de venta invalida")
+
+type SellPolicy struct {
+	Mode      string
+	Tolerance int64
+}
+
+// SellOutcome describe lo que le ocurrio a una wallet al aplicar la politica de venta
+type SellOutcome struct {
+	Seller       string
+	Mode         string
+	Competitor   bool
+	Disqualified bool
+	Deducted     *big.Int
+	SoldPercent  int64
+	Tolerance    int64
+}
+
+func ValidSellPolicy(mode string) bool {
+	switch mode {
+	case SellDisqualify, SellSubtract, SellTolerate:
+		return true
+	default:
+		return false
+	}
+}
+
+// ApplySellPolicy evalua una venta contra la politica del grupo y actualiza el ranking de la wallet
+func (c *Competition) ApplySellPolicy(id string, sale *Sale, policy SellPolicy) (*SellOutcome, error) {
+	if id == "" {
+		return nil, errrorEmptyID
+	}
+
+	if policy.Mode == "" {
+		policy.Mode = SellDisqualify
+	}
+
+	if !ValidSellPolicy(policy.Mode) {
+		return nil, errorInvalidPolicy
+	}
+
+	blacklist, err := c.GetBlacklist(id)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &SellOutcome{
+		Seller:    sale.Seller,
+		Mode:      policy.Mode,
+		Deducted:  big.NewInt(0),
+		Tolerance: policy.Tolerance,
+	}
+
+	purchases, err := c.GetComp(id)
+	if err != nil {
+		// Sin compras registradas la wallet no compite, solo se aplica la descalificacion
+		if policy.Mode != SellSubtract {
+			_, err := c.Ban(id, sale.Seller, reasonSold, "")
+			if err != nil && err != errorAlreadyDisqualified {
+				return nil, err
+			}
+			outcome.Disqualified = true
+		}
+		return outcome, nil
+	}
+
+	bought := purchases.TokensBoughtBy(sale.Seller)
+	sold := blacklist.TokensSoldBy(sale.Seller)
+
+	outcome.Competitor = bought.Sign() > 0
+	outcome.SoldPercent = 100
+	if outcome.Competitor {
+		percent := new(big.Int).Mul(sold, big.NewInt(100))
+		percent.Div(percent, bought)
+		outcome.SoldPercent = percent.Int64()
+	}
+
+	switch policy.Mode {
+	case SellSubtract:
+		outcome.Deducted = purchases.SubtractScore(sale.Seller, sale.Ton)
+		return outcome, nil
+	case SellTolerate:
+		if outcome.Competitor && outcome.SoldPercent < policy.Tolerance {
+			return outcome, nil
+		}
+	}
+
+	_, err = c.Ban(id, sale.Seller, reasonSold, "")
+	if err != nil && err != errorAlreadyDisqualified {
+		return nil, err
+	}
+	outcome.Disqualified = true
+
+	return outcome, nil
+}
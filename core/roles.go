@@ -0,0 +1,141 @@
+package core
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Roles de un grupo, cada uno incluye los permisos de los anteriores
+const (
+	RoleNone      = "none"
+	RoleModerator = "moderator"
+	RoleManager   = "manager"
+	RoleOwner     = "owner"
+)
+
+var roleRank = map[string]int{
+	RoleNone:      0,
+	RoleModerator: 1,
+	RoleManager:   2,
+	RoleOwner:     3,
+}
+
+var (
+	errorInvalidRole = errors.New("error: el rol no existe")
+	errorRoleNotSet  = errors.New("error: el usuario no tiene un rol asignado")
+)
+
+// ValidRole indica si role es un rol que se puede asignar
+func ValidRole(role string) bool {
+	_, exist := roleRank[role]
+	return exist
+}
+
+// RoleAllows indica si role tiene al menos los permisos de required
+func RoleAllows(role string, required string) bool {
+	return roleRank[role] >= roleRank[required]
+}
+
+// RoleAssignment es el rol que el creador de un grupo le dio a un usuario
+type RoleAssignment struct {
+	UserID   int64
+	Username string
+	Role     string
+}
+
+// Roles guarda los roles asignados en cada grupo, indexados por id de grupo y id de usuario de Telegram
+type Roles struct {
+	Assigned map[string]map[int64]*RoleAssignment
+	mutex    sync.RWMutex
+}
+
+func InitRoles() *Roles {
+	return &Roles{
+		Assigned: make(map[string]map[int64]*RoleAssignment),
+	}
+}
+
+// Set asigna el rol al usuario, RoleNone quita la asignacion
+func (r *Roles) Set(id string, userID int64, username string, role string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if id == "" || userID == 0 {
+		return errrorEmptyID
+	}
+
+	if !ValidRole(role) {
+		return errorInvalidRole
+	}
+
+	if role == RoleNone {
+		if _, exist := r.Assigned[id][userID]; !exist {
+			return errorRoleNotSet
+		}
+
+		delete(r.Assigned[id], userID)
+		return nil
+	}
+
+	if _, exist := r.Assigned[id]; !exist {
+		r.Assigned[id] = make(map[int64]*RoleAssignment)
+	}
+
+	r.Assigned[id][userID] = &RoleAssignment{UserID: userID, Username: username, Role: role}
+
+	return nil
+}
+
+// Get devuelve el rol asignado al usuario en el grupo
+func (r *Roles) Get(id string, userID int64) (string, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	assignment, exist := r.Assigned[id][userID]
+	if !exist {
+		return "", false
+	}
+
+	return assignment.Role, true
+}
+
+// List devuelve los roles asignados en el grupo, de mayor a menor rol
+func (r *Roles) List(id string) []RoleAssignment {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	assignments := make([]RoleAssignment, 0, len(r.Assigned[id]))
+	for _, assignment := range r.Assigned[id] {
+		assignments = append(assignments, *assignment)
+	}
+
+	sort.Slice(assignments, func(i, j int) bool {
+		if assignments[i].Role != assignments[j].Role {
+			return roleRank[assignments[i].Role] > roleRank[assignments[j].Role]
+		}
+		return assignments[i].UserID < assignments[j].UserID
+	})
+
+	return assignments
+}
+
+// Clear quita todos los roles asignados en el grupo
+func (r *Roles) Clear(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.Assigned, id)
+}
+
+func (r *Roles) Groups() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ids := make([]string, 0, len(r.Assigned))
+	for id := range r.Assigned {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
@@ -14,11 +14,14 @@ import (
 )
 
 var (
-	errorEmptyEvent       = errors.New("error: evento vacio")
-	errorNotExist         = errors.New("error: la operacion no existe")
-	errorAlreadyExist     = errors.New("error: la operacion ya existe")
-	errorSaleNotFound     = errors.New("error: venta no encontrada")
-	errorPurchaseNotFound = errors.New("error: compra no encontrada")
+	errorEmptyEvent          = errors.New("error: evento vacio")
+	errorNotExist            = errors.New("error: la operacion no existe")
+	errorAlreadyExist        = errors.New("error: la operacion ya existe")
+	errorSaleNotFound        = errors.New("error: venta no encontrada")
+	errorPurchaseNotFound    = errors.New("error: compra no encontrada")
+	errorBelowMinimum        = errors.New("error: la compra no alcanza el minimo de la competencia")
+	errorAlreadyDisqualified = errors.New("error: la wallet ya esta descalificada")
+	errorNotDisqualified     = errors.New("error: la wallet no esta descalificada")
 )
 
 type Sale struct {
@@ -32,13 +35,25 @@ type Sale struct {
 }
 
 type Sales struct {
-	Sale  map[string]*Sale
-	mutex sync.RWMutex
+	Sale map[string]*Sale
+	// Disqualified guarda las wallets descalificadas de la competencia
+	Disqualified map[string]*Disqualification
+	mutex        sync.RWMutex
+}
+
+// Disqualification registra quien descalifico a una wallet, por que, y las compras que se le retiraron
+type Disqualification struct {
+	Wallet    string
+	Reason    string
+	By        string
+	Timestamp int64
+	Purchases []*Purchase
 }
 
 func InitSales() *Sales {
 	return &Sales{
-		Sale: make(map[string]*Sale),
+		Sale:         make(map[string]*Sale),
+		Disqualified: make(map[string]*Disqualification),
 	}
 }
 
@@ -84,35 +99,35 @@ func (p *Sales) GetSaleHash(event *indexer.Event) (string, error) {
 	return hash, nil
 }
 
-func (s *Sales) AddSale(event *indexer.Event) error {
+func (s *Sales) AddSale(event *indexer.Event) (*Sale, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	decimals := big.NewInt(1000000000)
 
 	if event == nil {
-		return errorEmptyEvent
+		return nil, errorEmptyEvent
 	}
 
 	dataBytes, err := json.Marshal(event)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	hash := hash(dataBytes)
 
 	if _, exist := s.Sale[hash]; exist {
-		return errorAlreadyExist
+		return nil, errorAlreadyExist
 	}
 
 	seller, err := getters.GetAddress(event.Wallet)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	jettonAddr, err := getters.GetAddress(event.JettonAddress)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	jettonDecimal := convertToLargeInt(event.JettonDecimals)
@@ -129,9 +144,92 @@ func (s *Sales) AddSale(event *indexer.Event) error {
 
 	s.Sale[hash] = newSale
 
+	return newSale, nil
+}
+
+// List devuelve las ventas registradas
+func (s *Sales) List() []*Sale {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sales := make([]*Sale, 0, len(s.Sale))
+	for _, sale := range s.Sale {
+		sales = append(sales, sale)
+	}
+
+	return sales
+}
+
+func (s *Sales) TokensSoldBy(seller string) *big.Int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	total := big.NewInt(0)
+	for _, sale := range s.Sale {
+		if sale.Seller == seller {
+			total.Add(total, sale.Token)
+		}
+	}
+
+	return total
+}
+
+func (s *Sales) Disqualify(disqualification *Disqualification) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if disqualification == nil || disqualification.Wallet == "" {
+		return errrorEmptyID
+	}
+
+	if _, exist := s.Disqualified[disqualification.Wallet]; exist {
+		return errorAlreadyDisqualified
+	}
+
+	s.Disqualified[disqualification.Wallet] = disqualification
+
 	return nil
 }
 
+func (s *Sales) Reinstate(wallet string) (*Disqualification, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	disqualification, exist := s.Disqualified[wallet]
+	if !exist {
+		return nil, errorNotDisqualified
+	}
+
+	delete(s.Disqualified, wallet)
+
+	return disqualification, nil
+}
+
+func (s *Sales) DisqualifiedList() []*Disqualification {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	list := make([]*Disqualification, 0, len(s.Disqualified))
+	for _, disqualification := range s.Disqualified {
+		list = append(list, disqualification)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Timestamp < list[j].Timestamp
+	})
+
+	return list
+}
+
+func (s *Sales) IsDisqualified(wallet string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, exist := s.Disqualified[wallet]
+
+	return exist
+}
+
 func (s *Sales) RemoveSale(hash string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -153,6 +251,21 @@ type Purchase struct {
 	Buyer          string
 	Ton            *big.Int
 	Token          *big.Int
+	// Score es el TON que cuenta para el ranking, limitado por el maximo de la competencia
+	Score *big.Int
+	// Paused marca las compras hechas durante una pausa, se registran pero no entran al ranking
+	Paused bool
+}
+
+// Limits contiene los umbrales de compra de una competencia, en TON. Un valor 0 desactiva el umbral.
+// Exclusions y GroupID permiten descartar del ranking las wallets excluidas.
+type Limits struct {
+	MinBuy     int64
+	MaxBuy     int64
+	Exclusions *Exclusions
+	GroupID    string
+	// Paused registra la compra sin darle score mientras la competencia esta en pausa
+	Paused bool
 }
 
 type Purchases struct {
@@ -170,6 +283,10 @@ func (p *Purchases) StorePurchase(purchase *Purchase) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	if purchase.Score == nil {
+		purchase.Score = new(big.Int).Set(purchase.Ton)
+	}
+
 	dataBytes, err := json.Marshal(purchase)
 	if err != nil {
 		return err
@@ -209,15 +326,31 @@ func (p *Purchases) GetPurchaseHash(event *indexer.Event) (string, error) {
 	return hash, nil
 }
 
+// List devuelve todas las compras registradas, incluidas las que no entran al ranking
+func (p *Purchases) List() []*Purchase {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	purchases := make([]*Purchase, 0, len(p.Purchase))
+	for _, purchase := range p.Purchase {
+		purchases = append(purchases, purchase)
+	}
+
+	return purchases
+}
+
 func (p *Purchases) GetCompList() []*Purchase {
 	purchases := make([]*Purchase, 0)
 
 	for _, order := range p.Purchase {
+		if order.Paused {
+			continue
+		}
 		purchases = append(purchases, order)
 	}
 
 	sort.Slice(purchases, func(i, j int) bool {
-		return purchases[i].Ton.Cmp(purchases[j].Ton) > 0
+		return purchases[i].Score.Cmp(purchases[j].Score) > 0
 	})
 
 	if len(purchases) > 10 {
@@ -227,7 +360,7 @@ func (p *Purchases) GetCompList() []*Purchase {
 	return purchases
 }
 
-func (p *Purchases) AddPurchase(event *indexer.Event) (*Purchase, error) {
+func (p *Purchases) AddPurchase(event *indexer.Event, limits Limits) (*Purchase, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
@@ -248,6 +381,21 @@ func (p *Purchases) AddPurchase(event *indexer.Event) (*Purchase, error) {
 		return nil, errorAlreadyExist
 	}
 
+	ton := new(big.Int).Div(event.TonIn, decimals)
+
+	if limits.MinBuy > 0 && ton.Cmp(big.NewInt(limits.MinBuy)) < 0 {
+		return nil, errorBelowMinimum
+	}
+
+	score := new(big.Int).Set(ton)
+	if limits.MaxBuy > 0 && score.Cmp(big.NewInt(limits.MaxBuy)) > 0 {
+		score.SetInt64(limits.MaxBuy)
+	}
+
+	if limits.Paused {
+		score.SetInt64(0)
+	}
+
 	buyer, err := getters.GetAddress(event.Wallet)
 	if err != nil {
 		return nil, err
@@ -266,8 +414,14 @@ func (p *Purchases) AddPurchase(event *indexer.Event) (*Purchase, error) {
 		JettonSymbol:   event.JettonSymbol,
 		JettonDecimals: event.JettonDecimals,
 		Buyer:          buyer,
-		Ton:            new(big.Int).Div(event.TonIn, decimals),
+		Ton:            ton,
 		Token:          new(big.Int).Div(event.TokenOut, jettonDecimal),
+		Score:          score,
+		Paused:         limits.Paused,
+	}
+
+	if limits.Exclusions.IsExcluded(limits.GroupID, buyer) {
+		return newPurchase, ErrExcludedBuyer
 	}
 
 	p.Purchase[hash] = newPurchase
@@ -288,6 +442,72 @@ func (p *Purchases) GetPurchaseAndHashByBuyer(buyer string) (*Purchase, string,
 	return nil, "", errorCompNotExist
 }
 
+func (p *Purchases) TokensBoughtBy(buyer string) *big.Int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	total := big.NewInt(0)
+	for _, purchase := range p.Purchase {
+		if purchase.Buyer == buyer {
+			total.Add(total, purchase.Token)
+		}
+	}
+
+	return total
+}
+
+// RemovePurchasesByBuyer elimina todas las compras de una wallet y devuelve las compras removidas
+func (p *Purchases) RemovePurchasesByBuyer(buyer string) []*Purchase {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	removed := make([]*Purchase, 0)
+	for hash, purchase := range p.Purchase {
+		if purchase.Buyer == buyer {
+			removed = append(removed, purchase)
+			delete(p.Purchase, hash)
+		}
+	}
+
+	return removed
+}
+
+// SubtractScore descuenta un monto de TON del score de una wallet, empezando por su mayor compra.
+// Devuelve el monto realmente descontado.
+func (p *Purchases) SubtractScore(buyer string, amount *big.Int) *big.Int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	purchases := make([]*Purchase, 0)
+	for _, purchase := range p.Purchase {
+		if purchase.Buyer == buyer {
+			purchases = append(purchases, purchase)
+		}
+	}
+
+	sort.Slice(purchases, func(i, j int) bool {
+		return purchases[i].Score.Cmp(purchases[j].Score) > 0
+	})
+
+	remaining := new(big.Int).Set(amount)
+	for _, purchase := range purchases {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		if purchase.Score.Cmp(remaining) >= 0 {
+			purchase.Score = new(big.Int).Sub(purchase.Score, remaining)
+			remaining.SetInt64(0)
+			break
+		}
+
+		remaining.Sub(remaining, purchase.Score)
+		purchase.Score = big.NewInt(0)
+	}
+
+	return new(big.Int).Sub(amount, remaining)
+}
+
 func (s *Purchases) RemovePurchase(hash string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
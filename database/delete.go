@@ -28,3 +28,49 @@ func RemoveEndTimeData(client *sql.DB, id string) (bool, error) {
 	}
 	return true, nil
 }
+
+func RemoveDisqualifiedData(client *sql.DB, id string) (bool, error) {
+	sqlStatement := `DELETE FROM disqualified WHERE group_id = $1`
+	_, err := client.Exec(sqlStatement, id)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func RemoveAdminData(client *sql.DB, id int64) (bool, error) {
+	sqlStatement := `DELETE FROM bot_admins WHERE user_id = $1`
+	_, err := client.Exec(sqlStatement, id)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveGroupData elimina el grupo y todos sus datos: compras, ventas, fecha de culminacion, descalificados, roles y exclusiones
+func RemoveGroupData(client *sql.DB, id string) error {
+	tx, err := client.Begin()
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		`DELETE FROM order_buy WHERE group_id = $1`,
+		`DELETE FROM order_sell WHERE group_id = $1`,
+		`DELETE FROM end_time WHERE id = $1`,
+		`DELETE FROM disqualified WHERE group_id = $1`,
+		`DELETE FROM group_roles WHERE group_id = $1`,
+		`DELETE FROM excluded WHERE scope = $1`,
+		`DELETE FROM groups WHERE id = $1`,
+	}
+
+	for _, statement := range statements {
+		_, err = tx.Exec(statement, id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
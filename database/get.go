@@ -4,14 +4,23 @@ import (
 	"database/sql"
 	"fmt"
 	"math/big"
+	"strings"
+	"time"
 
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
 	"github.com/polarysfoundation/kilocompbot/bot/promotions"
 	"github.com/polarysfoundation/kilocompbot/core"
 	"github.com/polarysfoundation/kilocompbot/groups"
 )
 
 func GetGroups(db *sql.DB) ([]*groups.GroupData, error) {
-	row, err := db.Query(`SELECT * FROM groups`)
+	row, err := db.Query(`
+	SELECT id, comp_active, jetton_address, dedust_address, stonfi_address, emoji,
+		   min_buy, max_buy, sell_policy, sell_tolerance, announce_excluded,
+		   scheduled_start, scheduled_duration, reminders,
+		   live_board, board_message_id, buy_alerts, burst_threshold, burst_window,
+		   alert_media_file_id, alert_media_type, alert_template, locale, disabled
+	FROM groups`)
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +38,24 @@ func GetGroups(db *sql.DB) ([]*groups.GroupData, error) {
 			&group.Dedust,
 			&group.StonFi,
 			&group.Emoji,
+			&group.MinBuy,
+			&group.MaxBuy,
+			&group.SellPolicy,
+			&group.SellTolerance,
+			&group.AnnounceExcluded,
+			&group.ScheduledStart,
+			&group.ScheduledDuration,
+			&group.Reminders,
+			&group.LiveBoard,
+			&group.BoardMessageID,
+			&group.BuyAlerts,
+			&group.BurstThreshold,
+			&group.BurstWindow,
+			&group.AlertMediaFileID,
+			&group.AlertMediaType,
+			&group.AlertTemplate,
+			&group.Locale,
+			&group.Disabled,
 		)
 		if err != nil {
 			return nil, err
@@ -47,7 +74,7 @@ func GetGroups(db *sql.DB) ([]*groups.GroupData, error) {
 func GetPurchase(db *sql.DB, id string) ([]*core.Purchase, error) {
 	rows, err := db.Query(`
 	SELECT jetton_address, jetton_name, jetton_symbol, jetton_decimal,
-		   buyer_address, ton_amount, token_amount
+		   buyer_address, ton_amount, token_amount, COALESCE(score_amount, ton_amount), paused
 	FROM order_buy 
 	WHERE group_id = $1`, id)
 	if err != nil {
@@ -59,7 +86,7 @@ func GetPurchase(db *sql.DB, id string) ([]*core.Purchase, error) {
 
 	for rows.Next() {
 		var purchase core.Purchase
-		var tonAmount, tokenAmount, jettonDecimals string
+		var tonAmount, tokenAmount, scoreAmount, jettonDecimals string
 
 		err := rows.Scan(
 			&purchase.JettonAddress,
@@ -69,6 +96,8 @@ func GetPurchase(db *sql.DB, id string) ([]*core.Purchase, error) {
 			&purchase.Buyer,
 			&tonAmount,
 			&tokenAmount,
+			&scoreAmount,
+			&purchase.Paused,
 		)
 		if err != nil {
 			return nil, err
@@ -78,6 +107,7 @@ func GetPurchase(db *sql.DB, id string) ([]*core.Purchase, error) {
 		purchase.JettonDecimals, _ = new(big.Int).SetString(jettonDecimals, 10)
 		purchase.Ton, _ = new(big.Int).SetString(tonAmount, 10)
 		purchase.Token, _ = new(big.Int).SetString(tokenAmount, 10)
+		purchase.Score, _ = new(big.Int).SetString(scoreAmount, 10)
 
 		purchases = append(purchases, &purchase)
 	}
@@ -135,7 +165,7 @@ func GetSale(db *sql.DB, id string) ([]*core.Sale, error) {
 }
 
 func GetPromos(db *sql.DB, id string) (*promotions.Params, error) {
-	row := db.QueryRow(`SELECT ad_text, button_name, button_link, media FROM promo WHERE id = $1`, id)
+	row := db.QueryRow(`SELECT ad_text, button_name, button_link, media, media_file_id, media_type FROM promo WHERE id = $1`, id)
 
 	promo := &promotions.Params{} // Inicializa promo aquí
 
@@ -144,6 +174,8 @@ func GetPromos(db *sql.DB, id string) (*promotions.Params, error) {
 		&promo.ButtonName,
 		&promo.ButtonLink,
 		&promo.Media,
+		&promo.MediaFileID,
+		&promo.MediaType,
 	)
 
 	if err != nil {
@@ -156,21 +188,349 @@ func GetPromos(db *sql.DB, id string) (*promotions.Params, error) {
 	return promo, nil
 }
 
-func GetEndTime(db *sql.DB, id string) (int64, error) {
-	rows := db.QueryRow(`SELECT timestamp FROM end_time WHERE id = $1`, id)
+func GetCampaigns(db *sql.DB) ([]*promotions.Campaign, error) {
+	rows, err := db.Query(`SELECT id, ad_name, button_name, button_link, media_file_id, media_type, start_time, end_time, weight, target_groups FROM campaigns`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []*promotions.Campaign
+
+	for rows.Next() {
+		var campaign promotions.Campaign
+		var targets string
+
+		err := rows.Scan(
+			&campaign.ID,
+			&campaign.AdName,
+			&campaign.ButtonName,
+			&campaign.ButtonLink,
+			&campaign.MediaFileID,
+			&campaign.MediaType,
+			&campaign.Start,
+			&campaign.End,
+			&campaign.Weight,
+			&targets,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Los grupos destino se guardan separados por coma, vacio son todos los grupos
+		if targets != "" {
+			campaign.Groups = strings.Split(targets, ",")
+		}
+
+		campaigns = append(campaigns, &campaign)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return campaigns, nil
+}
+
+// GetLastCampaignID devuelve el mayor id de campaña usado, tambien los de campañas borradas que siguen en las estadisticas o reservas
+func GetLastCampaignID(db *sql.DB) (int64, error) {
+	var lastID int64
+
+	err := db.QueryRow(`SELECT GREATEST(
+		COALESCE((SELECT last_id FROM campaign_counter WHERE id = $1), 0),
+		COALESCE((SELECT MAX(id) FROM campaigns), 0),
+		COALESCE((SELECT MAX(campaign_id) FROM ad_stats), 0),
+		COALESCE((SELECT MAX(campaign_id) FROM ad_bookings), 0))`, campaignCounter).Scan(&lastID)
+	if err != nil {
+		return 0, err
+	}
+
+	return lastID, nil
+}
+
+func GetAdStats(db *sql.DB) ([]*promotions.AdStat, error) {
+	rows, err := db.Query(`SELECT campaign_id, group_id, impressions, clicks FROM ad_stats`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*promotions.AdStat
+
+	for rows.Next() {
+		var stat promotions.AdStat
+
+		err := rows.Scan(
+			&stat.CampaignID,
+			&stat.GroupID,
+			&stat.Impressions,
+			&stat.Clicks,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, &stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetBookings devuelve las reservas de anuncios creadas desde since
+func GetBookings(db *sql.DB, since int64) ([]*bookings.Booking, error) {
+	rows, err := db.Query(`SELECT comment, user_id, chat_id, ad_name, button_name, button_link, media_file_id, media_type, duration, price, created, expires, status, campaign_id, campaign_end, event_id FROM ad_bookings WHERE created >= $1`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*bookings.Booking
+
+	for rows.Next() {
+		var booking bookings.Booking
+		var duration int64
+		var price string
+
+		err := rows.Scan(
+			&booking.Comment,
+			&booking.UserID,
+			&booking.ChatID,
+			&booking.Ad.AdName,
+			&booking.Ad.ButtonName,
+			&booking.Ad.ButtonLink,
+			&booking.Ad.MediaFileID,
+			&booking.Ad.MediaType,
+			&duration,
+			&price,
+			&booking.Created,
+			&booking.Expires,
+			&booking.Status,
+			&booking.CampaignID,
+			&booking.CampaignEnd,
+			&booking.EventID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// El precio se guarda en nanotons como texto para no perder precision
+		amount, ok := new(big.Int).SetString(price, 10)
+		if !ok {
+			return nil, fmt.Errorf("precio invalido en la reserva %s: %s", booking.Comment, price)
+		}
+
+		booking.Price = amount
+		booking.Duration = time.Duration(duration) * time.Second
+
+		list = append(list, &booking)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetEndTime devuelve la fecha de culminacion y el momento de la pausa, 0 si no esta en pausa
+func GetEndTime(db *sql.DB, id string) (int64, int64, error) {
+	rows := db.QueryRow(`SELECT timestamp, paused_at FROM end_time WHERE id = $1`, id)
 
-	var timestamp int64
+	var timestamp, pausedAt int64
 
 	err := rows.Scan(
 		&timestamp,
+		&pausedAt,
 	)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	if err := rows.Err(); err != nil {
-		return 0, err
+		return 0, 0, err
+	}
+
+	return timestamp, pausedAt, nil
+}
+
+func GetDisqualified(db *sql.DB, id string) ([]*core.Disqualification, error) {
+	rows, err := db.Query(`SELECT wallet, reason, banned_by, timestamp FROM disqualified WHERE group_id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var disqualified []*core.Disqualification
+
+	for rows.Next() {
+		var disqualification core.Disqualification
+
+		err := rows.Scan(
+			&disqualification.Wallet,
+			&disqualification.Reason,
+			&disqualification.By,
+			&disqualification.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		disqualified = append(disqualified, &disqualification)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return disqualified, nil
+}
+
+func GetExcluded(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`SELECT scope, wallet FROM excluded`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	excluded := make(map[string][]string)
+
+	for rows.Next() {
+		var scope, wallet string
+
+		err := rows.Scan(&scope, &wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		excluded[scope] = append(excluded[scope], wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return excluded, nil
+}
+
+func GetRoles(db *sql.DB) (map[string][]*core.RoleAssignment, error) {
+	rows, err := db.Query(`SELECT group_id, user_id, username, role FROM group_roles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make(map[string][]*core.RoleAssignment)
+
+	for rows.Next() {
+		var id string
+		var assignment core.RoleAssignment
+
+		err := rows.Scan(&id, &assignment.UserID, &assignment.Username, &assignment.Role)
+		if err != nil {
+			return nil, err
+		}
+
+		roles[id] = append(roles[id], &assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// AuditEntry es una accion administrativa registrada en audit_log
+type AuditEntry struct {
+	ActorID       int64
+	ActorUsername string
+	GroupID       string
+	Action        string
+	Params        string
+	Timestamp     int64
+}
+
+func GetAudit(db *sql.DB, id string, limit int) ([]*AuditEntry, error) {
+	rows, err := db.Query(`
+	SELECT actor_id, actor_username, group_id, action, params, timestamp
+	FROM audit_log
+	WHERE group_id = $1
+	ORDER BY id DESC
+	LIMIT $2`, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+
+	for rows.Next() {
+		var entry AuditEntry
+
+		err := rows.Scan(
+			&entry.ActorID,
+			&entry.ActorUsername,
+			&entry.GroupID,
+			&entry.Action,
+			&entry.Params,
+			&entry.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// AdminEntry es un administrador del bot guardado en bot_admins
+type AdminEntry struct {
+	UserID    int64
+	Username  string
+	Role      string
+	AddedBy   int64
+	Timestamp int64
+}
+
+func GetAdmins(db *sql.DB) ([]*AdminEntry, error) {
+	rows, err := db.Query(`SELECT user_id, username, role, added_by, timestamp FROM bot_admins`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var admins []*AdminEntry
+
+	for rows.Next() {
+		var entry AdminEntry
+
+		err := rows.Scan(
+			&entry.UserID,
+			&entry.Username,
+			&entry.Role,
+			&entry.AddedBy,
+			&entry.Timestamp,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		admins = append(admins, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return timestamp, nil
+	return admins, nil
 }
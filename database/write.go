@@ -2,12 +2,30 @@ package database
 
 import (
 	"database/sql"
-	"math/big"
+	"strings"
+
+	"github.com/polarysfoundation/kilocompbot/bot/bookings"
+	"github.com/polarysfoundation/kilocompbot/bot/promotions"
+	"github.com/polarysfoundation/kilocompbot/core"
+	"github.com/polarysfoundation/kilocompbot/groups"
 )
 
-func WriteGroups(db *sql.DB, id string, compActive bool, jettonAddress string, dedust string, stonfi string, emoji string) error {
-	sqlStatement := "INSERT INTO groups (id, comp_active, jetton_address, dedust_address, stonfi_address, emoji) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (id) DO UPDATE SET comp_active = EXCLUDED.comp_active, jetton_address = EXCLUDED.jetton_address, dedust_address = EXCLUDED.dedust_address, stonfi_address = EXCLUDED.stonfi_address, emoji = EXCLUDED.emoji"
-	_, err := db.Exec(sqlStatement, id, compActive, jettonAddress, dedust, stonfi, emoji)
+// campaignCounter es la fila de campaign_counter que guarda el ultimo id de campaña asignado
+const campaignCounter = "campaigns"
+
+func WriteGroups(db *sql.DB, group *groups.GroupData) error {
+	sqlStatement := "INSERT INTO groups (id, comp_active, jetton_address, dedust_address, stonfi_address, emoji, min_buy, max_buy, sell_policy, sell_tolerance, announce_excluded, scheduled_start, scheduled_duration, reminders, live_board, board_message_id, buy_alerts, burst_threshold, burst_window, alert_media_file_id, alert_media_type, alert_template, locale, disabled) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24) ON CONFLICT (id) DO UPDATE SET comp_active = EXCLUDED.comp_active, jetton_address = EXCLUDED.jetton_address, dedust_address = EXCLUDED.dedust_address, stonfi_address = EXCLUDED.stonfi_address, emoji = EXCLUDED.emoji, min_buy = EXCLUDED.min_buy, max_buy = EXCLUDED.max_buy, sell_policy = EXCLUDED.sell_policy, sell_tolerance = EXCLUDED.sell_tolerance, announce_excluded = EXCLUDED.announce_excluded, scheduled_start = EXCLUDED.scheduled_start, scheduled_duration = EXCLUDED.scheduled_duration, reminders = EXCLUDED.reminders, live_board = EXCLUDED.live_board, board_message_id = EXCLUDED.board_message_id, buy_alerts = EXCLUDED.buy_alerts, burst_threshold = EXCLUDED.burst_threshold, burst_window = EXCLUDED.burst_window, alert_media_file_id = EXCLUDED.alert_media_file_id, alert_media_type = EXCLUDED.alert_media_type, alert_template = EXCLUDED.alert_template, locale = EXCLUDED.locale, disabled = EXCLUDED.disabled"
+	_, err := db.Exec(sqlStatement, group.ID, group.CompActive, group.JettonAddress, group.Dedust, group.StonFi, group.Emoji, group.MinBuy, group.MaxBuy, group.SellPolicy, group.SellTolerance, group.AnnounceExcluded, group.ScheduledStart, group.ScheduledDuration, group.Reminders, group.LiveBoard, group.BoardMessageID, group.BuyAlerts, group.BurstThreshold, group.BurstWindow, group.AlertMediaFileID, group.AlertMediaType, group.AlertTemplate, group.Locale, group.Disabled)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func WriteEndTime(db *sql.DB, id string, endTime int64, pausedAt int64) error {
+	sqlStatement := "INSERT INTO end_time (id, timestamp, paused_at) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET timestamp = EXCLUDED.timestamp, paused_at = EXCLUDED.paused_at"
+	_, err := db.Exec(sqlStatement, id, endTime, pausedAt)
 	if err != nil {
 		return err
 	}
@@ -15,38 +33,212 @@ func WriteGroups(db *sql.DB, id string, compActive bool, jettonAddress string, d
 	return nil
 }
 
-func WriteEndTime(db *sql.DB, id string, endTime int64) error {
-	sqlStatement := "INSERT INTO end_time (id, timestamp) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET timestamp = EXCLUDED.timestamp"
-	_, err := db.Exec(sqlStatement, id, endTime)
+// WritePurchases reemplaza las compras guardadas de un grupo por las compras actuales
+func WritePurchases(db *sql.DB, id string, purchases []*core.Purchase) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM order_buy WHERE group_id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, purchase := range purchases {
+		_, err = tx.Exec("INSERT INTO order_buy (group_id, jetton_address, jetton_name, jetton_symbol, jetton_decimal, buyer_address, ton_amount, token_amount, score_amount, paused) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)", id, purchase.JettonAddress, purchase.JettonName, purchase.JettonSymbol, purchase.JettonDecimals.Int64(), purchase.Buyer, purchase.Ton.Int64(), purchase.Token.Int64(), purchase.Score.Int64(), purchase.Paused)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// WriteSales reemplaza las ventas guardadas de un grupo por las ventas actuales
+func WriteSales(db *sql.DB, id string, sales []*core.Sale) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM order_sell WHERE group_id = $1", id)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
+	for _, sale := range sales {
+		_, err = tx.Exec("INSERT INTO order_sell (group_id, jetton_address, jetton_name, jetton_symbol, jetton_decimal, seller_address, ton_amount, token_amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)", id, sale.JettonAddress, sale.JettonName, sale.JettonSymbol, sale.JettonDecimals.Int64(), sale.Seller, sale.Ton.Int64(), sale.Token.Int64())
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func WritePromo(db *sql.DB, id string, adName string, buttonName string, buttonLink string, media string, mediaFileID string, mediaType string) error {
+	sqlStatement := "INSERT INTO promo (id, ad_text, button_name, button_link, media, media_file_id, media_type) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO UPDATE SET ad_text = EXCLUDED.ad_text, button_name = EXCLUDED.button_name, button_link = EXCLUDED.button_link, media = EXCLUDED.media, media_file_id = EXCLUDED.media_file_id, media_type = EXCLUDED.media_type"
+	_, err := db.Exec(sqlStatement, id, adName, buttonName, buttonLink, media, mediaFileID, mediaType)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-func WritePurchases(db *sql.DB, id string, jettonAddress string, jettonName string, jettonSymbol string, jettonDecimals *big.Int, buyer string, ton *big.Int, token *big.Int) error {
-	sqlStatement := "INSERT INTO order_buy (group_id, jetton_address, jetton_name, jetton_symbol, jetton_decimal, buyer_address, ton_amount, token_amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
-	_, err := db.Exec(sqlStatement, id, jettonAddress, jettonName, jettonSymbol, jettonDecimals.Int64(), buyer, ton.Int64(), token.Int64())
+// WriteCampaigns reemplaza las campañas guardadas por las campañas actuales y guarda el ultimo id asignado
+func WriteCampaigns(db *sql.DB, campaigns []promotions.Campaign, lastID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM campaigns")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, campaign := range campaigns {
+		_, err = tx.Exec("INSERT INTO campaigns (id, ad_name, button_name, button_link, media_file_id, media_type, start_time, end_time, weight, target_groups) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)", campaign.ID, campaign.AdName, campaign.ButtonName, campaign.ButtonLink, campaign.MediaFileID, campaign.MediaType, campaign.Start, campaign.End, campaign.Weight, strings.Join(campaign.Groups, ","))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	_, err = tx.Exec("INSERT INTO campaign_counter (id, last_id) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET last_id = GREATEST(campaign_counter.last_id, EXCLUDED.last_id)", campaignCounter, lastID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// WriteAdStats reemplaza las estadisticas de los anuncios por los contadores actuales
+func WriteAdStats(db *sql.DB, stats []promotions.AdStat) error {
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
 
+	_, err = tx.Exec("DELETE FROM ad_stats")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, stat := range stats {
+		_, err = tx.Exec("INSERT INTO ad_stats (campaign_id, group_id, impressions, clicks) VALUES ($1, $2, $3, $4)", stat.CampaignID, stat.GroupID, stat.Impressions, stat.Clicks)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// WriteDisqualified reemplaza las wallets descalificadas de un grupo por la lista actual
+func WriteDisqualified(db *sql.DB, id string, disqualified []*core.Disqualification) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM disqualified WHERE group_id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, disqualification := range disqualified {
+		_, err = tx.Exec("INSERT INTO disqualified (group_id, wallet, reason, banned_by, timestamp) VALUES ($1, $2, $3, $4, $5)", id, disqualification.Wallet, disqualification.Reason, disqualification.By, disqualification.Timestamp)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// WriteExcluded reemplaza la lista de exclusion de un scope por la lista actual
+func WriteExcluded(db *sql.DB, scope string, wallets []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM excluded WHERE scope = $1", scope)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, wallet := range wallets {
+		_, err = tx.Exec("INSERT INTO excluded (scope, wallet) VALUES ($1, $2)", scope, wallet)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// WriteRoles reemplaza los roles de un grupo por los roles actuales
+func WriteRoles(db *sql.DB, id string, roles []core.RoleAssignment) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM group_roles WHERE group_id = $1", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, role := range roles {
+		_, err = tx.Exec("INSERT INTO group_roles (group_id, user_id, username, role) VALUES ($1, $2, $3, $4)", id, role.UserID, role.Username, role.Role)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func WriteAudit(db *sql.DB, entry *AuditEntry) error {
+	sqlStatement := "INSERT INTO audit_log (actor_id, actor_username, group_id, action, params, timestamp) VALUES ($1, $2, $3, $4, $5, $6)"
+	_, err := db.Exec(sqlStatement, entry.ActorID, entry.ActorUsername, entry.GroupID, entry.Action, entry.Params, entry.Timestamp)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-func WriteSales(db *sql.DB, id string, jettonAddress string, jettonName string, jettonSymbol string, jettonDecimals *big.Int, seller string, ton *big.Int, token *big.Int) error {
-	sqlStatement := "INSERT INTO order_sell (group_id, jetton_address, jetton_name, jetton_symbol, jetton_decimal, seller_address, ton_amount, token_amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
-	_, err := db.Exec(sqlStatement, id, jettonAddress, jettonName, jettonSymbol, jettonDecimals.Int64(), seller, ton.Int64(), token.Int64())
+// WriteBooking guarda una reserva de anuncio, si ya existe se actualiza su estado y la campaña que activo
+func WriteBooking(db *sql.DB, booking *bookings.Booking) error {
+	sqlStatement := "INSERT INTO ad_bookings (comment, user_id, chat_id, ad_name, button_name, button_link, media_file_id, media_type, duration, price, created, expires, status, campaign_id, campaign_end, event_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) ON CONFLICT (comment) DO UPDATE SET status = EXCLUDED.status, campaign_id = EXCLUDED.campaign_id, campaign_end = EXCLUDED.campaign_end, event_id = EXCLUDED.event_id"
+	_, err := db.Exec(sqlStatement, booking.Comment, booking.UserID, booking.ChatID, booking.Ad.AdName, booking.Ad.ButtonName, booking.Ad.ButtonLink, booking.Ad.MediaFileID, booking.Ad.MediaType, int64(booking.Duration.Seconds()), booking.Price.String(), booking.Created, booking.Expires, booking.Status, booking.CampaignID, booking.CampaignEnd, booking.EventID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func WritePromo(db *sql.DB, id string, adName string, buttonName string, buttonLink string, media string) error {
-	sqlStatement := "INSERT INTO promo (id, ad_text, button_name, button_link, media) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (id) DO UPDATE SET ad_text = EXCLUDED.ad_text, button_name = EXCLUDED.button_name, button_link = EXCLUDED.button_link, media = EXCLUDED.media"
-	_, err := db.Exec(sqlStatement, id, adName, buttonName, buttonLink, media)
+// WriteAdmin guarda un administrador del bot, si ya existe se actualiza su usuario y su rol
+func WriteAdmin(db *sql.DB, entry *AdminEntry) error {
+	sqlStatement := "INSERT INTO bot_admins (user_id, username, role, added_by, timestamp) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, role = EXCLUDED.role"
+	_, err := db.Exec(sqlStatement, entry.UserID, entry.Username, entry.Role, entry.AddedBy, entry.Timestamp)
 	if err != nil {
 		return err
 	}
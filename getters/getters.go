@@ -119,3 +119,38 @@ func IsAddress(address string) (bool, error) {
 
 	return false, nil
 }
+
+// NormalizeAddress devuelve la direccion en formato bounceable b64url, el mismo que usan las compras
+func NormalizeAddress(address string) (string, error) {
+	url := detectAddress(address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("error al crear la solicitud HTTP: %v", err)
+	}
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error al realizar la solicitud HTTP: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return "", fmt.Errorf("error al leer la respuesta HTTP: %v", err)
+	}
+
+	if data, ok := result["result"].(map[string]interface{}); ok {
+		if bounceable, ok := data["bounceable"].(map[string]interface{}); ok {
+			if addrb64, ok := bounceable["b64url"].(string); ok && addrb64 != "" {
+				return addrb64, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("error: direccion invalida %s", address)
+}
@@ -3,8 +3,10 @@ package groups
 import (
 	"errors"
 	"log"
+	"sort"
 	"sync"
 
+	"github.com/polarysfoundation/kilocompbot/core"
 	"github.com/polarysfoundation/kilocompbot/indexer"
 )
 
@@ -23,6 +25,34 @@ type GroupData struct {
 	Dedust        string
 	StonFi        string
 	Emoji         string
+	MinBuy        int64
+	MaxBuy        int64
+	SellPolicy    string
+	SellTolerance int64
+	// AnnounceExcluded anuncia las compras de wallets excluidas aunque no entren al ranking
+	AnnounceExcluded bool
+	// ScheduledStart y ScheduledDuration guardan una competencia programada con /schedulecomp
+	ScheduledStart    int64
+	ScheduledDuration int64
+	// Reminders es la lista de avisos de tiempo restante, por ejemplo "24h,6h,1h,10m"
+	Reminders string
+	// LiveBoard fija un leaderboard al iniciar la competencia y lo edita con cada cambio del ranking
+	LiveBoard      bool
+	BoardMessageID int
+	// BuyAlerts define como se anuncia cada compra: full, compact u off
+	BuyAlerts string
+	// Si llegan mas de BurstThreshold compras en BurstWindow segundos se agrupan en un resumen, 0 lo desactiva
+	BurstThreshold int64
+	BurstWindow    int64
+	// AlertMediaFileID y AlertMediaType reemplazan la media global en los anuncios del grupo
+	AlertMediaFileID string
+	AlertMediaType   string
+	// AlertTemplate reemplaza el texto del anuncio de compra, admite {buyer}, {ton}, {tokens}, {rank}, {ends_in}...
+	AlertTemplate string
+	// Locale es el idioma de los mensajes del grupo, se cambia con /language
+	Locale string
+	// Disabled lo activa un administrador del bot desde el panel, el bot ignora los comandos y las compras del grupo
+	Disabled bool
 }
 
 type Groups struct {
@@ -64,7 +94,121 @@ func (g *Groups) UpdateCompStatus(chatID string, update bool) error {
 }
 
 func (g *Groups) CompStatus(id string) bool {
-	return g.ActiveGroups[id].CompActive
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	group, exist := g.ActiveGroups[id]
+	if !exist {
+		return false
+	}
+
+	return group.CompActive
+}
+
+func (g *Groups) SetDisabled(id string, disabled bool) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	group, exist := g.ActiveGroups[id]
+	if !exist {
+		return errorNoExist
+	}
+
+	group.Disabled = disabled
+
+	return nil
+}
+
+// SetAlertMedia cambia la media de los anuncios de compra del grupo, vacia usa la media global
+func (g *Groups) SetAlertMedia(id string, fileID string, mediaType string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	group, exist := g.ActiveGroups[id]
+	if !exist {
+		return errorNoExist
+	}
+
+	group.AlertMediaFileID = fileID
+	group.AlertMediaType = mediaType
+
+	return nil
+}
+
+// AlertMedia devuelve el file_id y el tipo de la media de los anuncios del grupo
+func (g *Groups) AlertMedia(id string) (string, string) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	group, exist := g.ActiveGroups[id]
+	if !exist {
+		return "", ""
+	}
+
+	return group.AlertMediaFileID, group.AlertMediaType
+}
+
+// ClearAlertMedia descarta la media del grupo si sigue siendo fileID, devuelve false si ya se habia cambiado
+func (g *Groups) ClearAlertMedia(id string, fileID string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	group, exist := g.ActiveGroups[id]
+	if !exist || group.AlertMediaFileID != fileID {
+		return false
+	}
+
+	group.AlertMediaFileID = ""
+	group.AlertMediaType = ""
+
+	return true
+}
+
+// SetBoardMessage guarda el mensaje del leaderboard fijado del grupo, 0 indica que no hay ninguno
+func (g *Groups) SetBoardMessage(id string, messageID int) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	group, exist := g.ActiveGroups[id]
+	if !exist {
+		return errorNoExist
+	}
+
+	group.BoardMessageID = messageID
+
+	return nil
+}
+
+func (g *Groups) RemoveGroup(id string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if id == "" {
+		return errorEmptyID
+	}
+
+	if _, exist := g.ActiveGroups[id]; !exist {
+		return errorNoExist
+	}
+
+	delete(g.ActiveGroups, id)
+
+	return nil
+}
+
+// IDs devuelve los ids de los grupos registrados ordenados
+func (g *Groups) IDs() []string {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	ids := make([]string, 0, len(g.ActiveGroups))
+	for id := range g.ActiveGroups {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
 }
 
 func (g *Groups) GroupExist(id string) bool {
@@ -131,6 +275,10 @@ func (g *Groups) AddGroup(id string) error {
 		JettonAddress: "",
 		Dedust:        "",
 		StonFi:        "",
+		SellPolicy:    core.SellDisqualify,
+		Reminders:     core.DefaultReminders,
+		BuyAlerts:     "full",
+		Locale:        "en",
 	}
 
 	g.ActiveGroups[id] = newGroup
@@ -190,6 +338,13 @@ func (t *ActiveTemps) AddTemp(id string) error {
 	return nil
 }
 
+func (t *ActiveTemps) RemoveTemp(id string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.TempSetter, id)
+}
+
 func (t *ActiveTemps) ChangeTemp(typeTemp int, id string, update bool) error {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
@@ -0,0 +1,107 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// transfersLimit es la cantidad de transacciones recientes que se revisan en cada consulta
+const transfersLimit = 50
+
+// Transfer es una transferencia de TON recibida por una cuenta, Comment es el texto adjunto
+type Transfer struct {
+	EventID   string
+	Sender    string
+	Amount    *big.Int
+	Comment   string
+	Timestamp int64
+}
+
+// TonAPI lee las transferencias recibidas desde tonapi.io
+type TonAPI struct {
+	Key string
+}
+
+// IncomingTransfers devuelve las transferencias de TON recibidas por address entre sus transacciones mas recientes.
+// Se leen las transacciones de la cuenta y no sus eventos porque el in_msg de una transaccion siempre llega a la cuenta.
+func (t *TonAPI) IncomingTransfers(address string) ([]*Transfer, error) {
+	endpoint := fmt.Sprintf("https://tonapi.io/v2/blockchain/accounts/%s/transactions?limit=%d", url.PathEscape(address), transfersLimit)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error al crear la solicitud HTTP: %v", err)
+	}
+	req.Header.Set("X-API-KEY", t.Key)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al realizar la solicitud HTTP: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error: tonapi respondio con el estado %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Transactions []struct {
+			Hash    string `json:"hash"`
+			Utime   int64  `json:"utime"`
+			Success bool   `json:"success"`
+			Aborted bool   `json:"aborted"`
+			InMsg   *struct {
+				Value  json.Number `json:"value"`
+				Source *struct {
+					Address string `json:"address"`
+				} `json:"source"`
+				DecodedOpName string `json:"decoded_op_name"`
+				DecodedBody   struct {
+					Text string `json:"text"`
+				} `json:"decoded_body"`
+			} `json:"in_msg"`
+		} `json:"transactions"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la respuesta HTTP: %v", err)
+	}
+
+	transfers := make([]*Transfer, 0)
+
+	for _, tx := range result.Transactions {
+		// Los mensajes externos no tienen source y los fallidos devuelven los TON al remitente
+		if !tx.Success || tx.Aborted || tx.InMsg == nil || tx.InMsg.Source == nil {
+			continue
+		}
+
+		amount, ok := new(big.Int).SetString(tx.InMsg.Value.String(), 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+
+		comment := ""
+		if tx.InMsg.DecodedOpName == "text_comment" {
+			comment = tx.InMsg.DecodedBody.Text
+		}
+
+		transfers = append(transfers, &Transfer{
+			EventID:   tx.Hash,
+			Sender:    tx.InMsg.Source.Address,
+			Amount:    amount,
+			Comment:   comment,
+			Timestamp: tx.Utime,
+		})
+	}
+
+	return transfers, nil
+}
@@ -31,7 +31,7 @@ func main() {
 
 	defer client.Close()
 
-	bot, err := bot.InitBot(cfg.BotToken, client, ctx, cfg.TONCenterAPI)
+	bot, err := bot.InitBot(cfg, client, ctx)
 	if err != nil {
 		log.Fatalf("Error iniciando el bot: %v", err)
 	}
@@ -4,7 +4,25 @@ CREATE TABLE groups(
     jetton_address TEXT NOT NULL,
     dedust_address TEXT NOT NULL,
     stonfi_address TEXT NOT NULL,
-    emoji TEXT NOT NULL
+    emoji TEXT NOT NULL,
+    min_buy NUMERIC NOT NULL DEFAULT 0,
+    max_buy NUMERIC NOT NULL DEFAULT 0,
+    sell_policy TEXT NOT NULL DEFAULT 'disqualify',
+    sell_tolerance NUMERIC NOT NULL DEFAULT 0,
+    announce_excluded BOOLEAN NOT NULL DEFAULT FALSE,
+    scheduled_start NUMERIC NOT NULL DEFAULT 0,
+    scheduled_duration NUMERIC NOT NULL DEFAULT 0,
+    reminders TEXT NOT NULL DEFAULT '24h,6h,1h,10m',
+    live_board BOOLEAN NOT NULL DEFAULT FALSE,
+    board_message_id BIGINT NOT NULL DEFAULT 0,
+    buy_alerts TEXT NOT NULL DEFAULT 'full',
+    burst_threshold NUMERIC NOT NULL DEFAULT 0,
+    burst_window NUMERIC NOT NULL DEFAULT 0,
+    alert_media_file_id TEXT NOT NULL DEFAULT '',
+    alert_media_type TEXT NOT NULL DEFAULT '',
+    alert_template TEXT NOT NULL DEFAULT '',
+    locale TEXT NOT NULL DEFAULT 'en',
+    disabled BOOLEAN NOT NULL DEFAULT FALSE
 );
 CREATE TABLE order_buy(
     id SERIAL PRIMARY KEY,
@@ -15,7 +33,9 @@ CREATE TABLE order_buy(
     jetton_decimal NUMERIC NOT NULL,
     buyer_address TEXT NOT NULL,
     ton_amount NUMERIC NOT NULL,
-    token_amount NUMERIC NOT NULL
+    token_amount NUMERIC NOT NULL,
+    score_amount NUMERIC,
+    paused BOOLEAN NOT NULL DEFAULT FALSE
 );
 CREATE TABLE order_sell(
     id SERIAL PRIMARY KEY,
@@ -33,9 +53,89 @@ CREATE TABLE promo(
     ad_text TEXT NOT NULL,
     button_name TEXT NOT NULL,
     button_link TEXT NOT NULL,
-    media TEXT NOT NULL
+    media TEXT NOT NULL,
+    media_file_id TEXT NOT NULL DEFAULT '',
+    media_type TEXT NOT NULL DEFAULT 'video'
 );
 CREATE TABLE end_time(
     id TEXT UNIQUE PRIMARY KEY REFERENCES groups(id),
+    timestamp NUMERIC NOT NULL,
+    paused_at NUMERIC NOT NULL DEFAULT 0
+);
+CREATE TABLE disqualified(
+    group_id TEXT REFERENCES groups(id),
+    wallet TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    banned_by TEXT NOT NULL DEFAULT '',
+    timestamp NUMERIC NOT NULL DEFAULT 0,
+    UNIQUE (group_id, wallet)
+);
+CREATE TABLE excluded(
+    scope TEXT NOT NULL,
+    wallet TEXT NOT NULL,
+    UNIQUE (scope, wallet)
+);
+CREATE TABLE audit_log(
+    id SERIAL PRIMARY KEY,
+    actor_id BIGINT NOT NULL,
+    actor_username TEXT NOT NULL,
+    group_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    params TEXT NOT NULL,
     timestamp NUMERIC NOT NULL
-);
\ No newline at end of file
+);
+CREATE TABLE bot_admins(
+    user_id BIGINT PRIMARY KEY,
+    username TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'admin',
+    added_by BIGINT NOT NULL DEFAULT 0,
+    timestamp NUMERIC NOT NULL DEFAULT 0
+);
+CREATE TABLE group_roles(
+    group_id TEXT NOT NULL,
+    user_id BIGINT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL,
+    UNIQUE (group_id, user_id)
+);
+CREATE TABLE campaigns(
+    id BIGINT PRIMARY KEY,
+    ad_name TEXT NOT NULL,
+    button_name TEXT NOT NULL,
+    button_link TEXT NOT NULL,
+    media_file_id TEXT NOT NULL DEFAULT '',
+    media_type TEXT NOT NULL DEFAULT '',
+    start_time NUMERIC NOT NULL,
+    end_time NUMERIC NOT NULL,
+    weight NUMERIC NOT NULL DEFAULT 1,
+    target_groups TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE ad_bookings(
+    comment TEXT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    chat_id BIGINT NOT NULL,
+    ad_name TEXT NOT NULL,
+    button_name TEXT NOT NULL,
+    button_link TEXT NOT NULL,
+    media_file_id TEXT NOT NULL DEFAULT '',
+    media_type TEXT NOT NULL DEFAULT '',
+    duration NUMERIC NOT NULL,
+    price TEXT NOT NULL,
+    created NUMERIC NOT NULL,
+    expires NUMERIC NOT NULL,
+    status TEXT NOT NULL,
+    campaign_id BIGINT NOT NULL DEFAULT 0,
+    campaign_end NUMERIC NOT NULL DEFAULT 0,
+    event_id TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE ad_stats(
+    campaign_id BIGINT NOT NULL,
+    group_id TEXT NOT NULL,
+    impressions NUMERIC NOT NULL DEFAULT 0,
+    clicks NUMERIC NOT NULL DEFAULT 0,
+    UNIQUE (campaign_id, group_id)
+);
+CREATE TABLE campaign_counter(
+    id TEXT PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0
+);
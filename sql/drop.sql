@@ -1,8 +1,16 @@
 DROP TABLE IF EXISTS promo CASCADE;
+DROP TABLE IF EXISTS campaigns CASCADE;
+DROP TABLE IF EXISTS ad_bookings CASCADE;
+DROP TABLE IF EXISTS ad_stats CASCADE;
+DROP TABLE IF EXISTS audit_log CASCADE;
+DROP TABLE IF EXISTS bot_admins CASCADE;
 
 -- Eliminar las tablas que dependen de 'groups' primero
 DROP TABLE IF EXISTS order_buy CASCADE;
 DROP TABLE IF EXISTS order_sell CASCADE;
+DROP TABLE IF EXISTS disqualified CASCADE;
+DROP TABLE IF EXISTS excluded CASCADE;
+DROP TABLE IF EXISTS group_roles CASCADE;
 -- Finalmente, eliminar la tabla 'active_groups'
 DROP TABLE IF EXISTS end_time CASCADE;
 DROP TABLE IF EXISTS groups CASCADE;
\ No newline at end of file
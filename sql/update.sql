@@ -0,0 +1,102 @@
+-- Cambios para bases de datos creadas con una version anterior de create.sql
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS min_buy NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS max_buy NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE order_buy ADD COLUMN IF NOT EXISTS score_amount NUMERIC;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS sell_policy TEXT NOT NULL DEFAULT 'disqualify';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS sell_tolerance NUMERIC NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS disqualified(
+    group_id TEXT REFERENCES groups(id),
+    wallet TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    banned_by TEXT NOT NULL DEFAULT '',
+    timestamp NUMERIC NOT NULL DEFAULT 0,
+    UNIQUE (group_id, wallet)
+);
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS announce_excluded BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS excluded(
+    scope TEXT NOT NULL,
+    wallet TEXT NOT NULL,
+    UNIQUE (scope, wallet)
+);
+CREATE TABLE IF NOT EXISTS audit_log(
+    id SERIAL PRIMARY KEY,
+    actor_id BIGINT NOT NULL,
+    actor_username TEXT NOT NULL,
+    group_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    params TEXT NOT NULL,
+    timestamp NUMERIC NOT NULL
+);
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS scheduled_start NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS scheduled_duration NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE end_time ADD COLUMN IF NOT EXISTS paused_at NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS reminders TEXT NOT NULL DEFAULT '24h,6h,1h,10m';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS live_board BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS board_message_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS buy_alerts TEXT NOT NULL DEFAULT 'full';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS burst_threshold NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS burst_window NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE promo ADD COLUMN IF NOT EXISTS media_file_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE promo ADD COLUMN IF NOT EXISTS media_type TEXT NOT NULL DEFAULT 'video';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS alert_media_file_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS alert_media_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS alert_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en';
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE order_buy ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS bot_admins(
+    user_id BIGINT PRIMARY KEY,
+    username TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'admin',
+    added_by BIGINT NOT NULL DEFAULT 0,
+    timestamp NUMERIC NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS group_roles(
+    group_id TEXT NOT NULL,
+    user_id BIGINT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL,
+    UNIQUE (group_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS campaigns(
+    id BIGINT PRIMARY KEY,
+    ad_name TEXT NOT NULL,
+    button_name TEXT NOT NULL,
+    button_link TEXT NOT NULL,
+    media_file_id TEXT NOT NULL DEFAULT '',
+    media_type TEXT NOT NULL DEFAULT '',
+    start_time NUMERIC NOT NULL,
+    end_time NUMERIC NOT NULL,
+    weight NUMERIC NOT NULL DEFAULT 1,
+    target_groups TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS ad_bookings(
+    comment TEXT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    chat_id BIGINT NOT NULL,
+    ad_name TEXT NOT NULL,
+    button_name TEXT NOT NULL,
+    button_link TEXT NOT NULL,
+    media_file_id TEXT NOT NULL DEFAULT '',
+    media_type TEXT NOT NULL DEFAULT '',
+    duration NUMERIC NOT NULL,
+    price TEXT NOT NULL,
+    created NUMERIC NOT NULL,
+    expires NUMERIC NOT NULL,
+    status TEXT NOT NULL,
+    campaign_id BIGINT NOT NULL DEFAULT 0,
+    campaign_end NUMERIC NOT NULL DEFAULT 0,
+    event_id TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS ad_stats(
+    campaign_id BIGINT NOT NULL,
+    group_id TEXT NOT NULL,
+    impressions NUMERIC NOT NULL DEFAULT 0,
+    clicks NUMERIC NOT NULL DEFAULT 0,
+    UNIQUE (campaign_id, group_id)
+);
+CREATE TABLE IF NOT EXISTS campaign_counter(
+    id TEXT PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0
+);
+ALTER TABLE ad_bookings ADD COLUMN IF NOT EXISTS campaign_end NUMERIC NOT NULL DEFAULT 0;